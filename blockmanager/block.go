@@ -6,6 +6,8 @@ type Block struct {
 	records       []*Record
 	blockNumber   uint64
 	blockFilePath string
+	corrupt       bool   // citanje je stalo na ostecenom rekordu, ostatak bloka nije procitan
+	corruptOffset uint64 // pomeraj ostecenog rekorda od pocetka bloka
 }
 
 func (block *Block) GetRecords() []*Record {
@@ -18,8 +20,20 @@ func (block *Block) GetBlockFilePath() string {
 	return block.blockFilePath
 }
 
+// GetCorruption vraca pomeraj prvog ostecenog rekorda u bloku i da li ga ima
+func (block *Block) GetCorruption() (uint64, bool) {
+	return block.corruptOffset, block.corrupt
+}
+
+func (block *Block) setCorrupt(offset uint64) {
+	block.corrupt = true
+	block.corruptOffset = offset
+}
+
+// SetRecords menja sadrzaj bloka; blok se posle toga upisuje ceo, pa ostecen ostatak vise ne postoji
 func (b *Block) SetRecords(records []*Record) {
 	b.records = records
+	b.corrupt = false
 }
 
 func (b *Block) SetBlockNumber(num uint64) {
@@ -24,6 +24,9 @@ da bi se zapisao(za sada samo) broj bloka da bi mogao da se procita fajl ako se
 func (blockManager *BlockManager) EmptyBufferPool()
 
 func (blockManager *BlockManager) CheckPoolCapacity() bool
+
+func (blockManager *BlockManager) Sync() error - prazni baferpul i radi fsync svih fajlova u koje je pisano od prethodnog Sync-a,
+tek posle njega su blokovi sigurni i ako padne ceo sistem (ne samo program)
 */
 
 package blockmanager
@@ -34,6 +37,7 @@ import (
 	"hash/crc32"
 	"io"
 	"os"
+	"sync"
 )
 
 const (
@@ -56,8 +60,12 @@ type BlockManager struct {
 	bufferPoolSize uint64
 }
 
+// BufferPool moze da deli vise BlockManager-a (npr. WAL sa privremenim menadzerom za drugu velicinu bloka),
+// pa lock cuva blokove bez obzira kroz koji menadzer im se pristupa
 type BufferPool struct {
-	blocks []*Block
+	blocks   []*Block
+	unsynced map[string]bool // fajlovi u koje su blokovi upisani posle poslednjeg Sync-a
+	lock     sync.Mutex
 }
 
 // geteri=================================================
@@ -72,6 +80,8 @@ func (blockManager *BlockManager) GetBufferPoolSize() uint64 {
 	return blockManager.bufferPoolSize
 }
 func (bufferPool *BufferPool) GetBlocks() []*Block {
+	bufferPool.lock.Lock()
+	defer bufferPool.lock.Unlock()
 	return bufferPool.blocks
 }
 
@@ -90,6 +100,8 @@ func (bm *BlockManager) SetBufferPoolSize(size uint64) {
 }
 
 func (bp *BufferPool) SetBlocks(blocks []*Block) {
+	bp.lock.Lock()
+	defer bp.lock.Unlock()
 	bp.blocks = blocks
 }
 
@@ -107,7 +119,14 @@ func NewBufferPool() *BufferPool {
 }
 
 func (blockManager *BlockManager) ReadBlock(fileName string, blockNum uint64) *Block {
-	block := blockManager.bufferPool.CheckForBlock(blockNum, fileName)
+	blockManager.bufferPool.lock.Lock()
+	defer blockManager.bufferPool.lock.Unlock()
+	return blockManager.readBlock(fileName, blockNum)
+}
+
+// readBlock radi isto sto i ReadBlock; pozivalac drzi lock buffer pool-a
+func (blockManager *BlockManager) readBlock(fileName string, blockNum uint64) *Block {
+	block := blockManager.bufferPool.checkForBlock(blockNum, fileName)
 	if block != nil {
 		return block
 	}
@@ -129,11 +148,11 @@ func (blockManager *BlockManager) ReadBlock(fileName string, blockNum uint64) *B
 	if end == 0 {
 		newBlock := &Block{blockFilePath: fileName, blockNumber: blockNum}
 		blockManager.bufferPool.blocks = append(blockManager.bufferPool.blocks, newBlock)
-		flushed := blockManager.CheckPoolCapacity()
+		flushed := blockManager.checkPoolCapacity()
 		if !flushed {
 			blockManager.WriteBlock(newBlock.records, fileName, newBlock.blockNumber) //cisto da se zauzme prostor posto ce se kasnije isprazniti ali ako ne napisem makar prazan blok zabosce brojenje blokova, nije napisan pa se ni ne broji ali je u bafer pulu
 		}
-		return blockManager.ReadBlock(fileName, blockNum) //ako dodavanje pokrene praznjenje baferpula mora da se pozove readblock opet da bi se procitao i vratio
+		return blockManager.readBlock(fileName, blockNum) //ako dodavanje pokrene praznjenje baferpula mora da se pozove readblock opet da bi se procitao i vratio
 	}
 
 	block = &Block{}
@@ -143,16 +162,26 @@ func (blockManager *BlockManager) ReadBlock(fileName string, blockNum uint64) *B
 	start := 0
 	for {
 		record, err := Deserialize(data[start:])
+		if err == DESERIALIZE_CORRUPT {
+			block.setCorrupt(uint64(start))
+		}
 		if err != 0 {
 			break
 		}
 		block.records = append(block.records, record)
 		start += int(record.recordSize)
 	}
-	blockManager.CheckPoolCapacity()
+	blockManager.checkPoolCapacity()
 	return block
 }
 func (bufferPool *BufferPool) CheckForBlock(blockNum uint64, filePath string) *Block {
+	bufferPool.lock.Lock()
+	defer bufferPool.lock.Unlock()
+	return bufferPool.checkForBlock(blockNum, filePath)
+}
+
+// checkForBlock radi isto sto i CheckForBlock; pozivalac drzi lock
+func (bufferPool *BufferPool) checkForBlock(blockNum uint64, filePath string) *Block {
 	for _, block := range bufferPool.blocks {
 		if block.blockNumber == blockNum && block.blockFilePath == filePath {
 			return block
@@ -182,29 +211,79 @@ func CRC32(data []byte) uint32 {
 	return crc32.ChecksumIEEE(data)
 }
 func (blockManager *BlockManager) CheckPoolCapacity() bool {
+	blockManager.bufferPool.lock.Lock()
+	defer blockManager.bufferPool.lock.Unlock()
+	return blockManager.checkPoolCapacity()
+}
+
+// checkPoolCapacity radi isto sto i CheckPoolCapacity; pozivalac drzi lock buffer pool-a
+func (blockManager *BlockManager) checkPoolCapacity() bool {
 	poolCapacity := 0
 	for range blockManager.bufferPool.blocks {
 		poolCapacity += int(blockManager.blockSize)
 	}
 	if poolCapacity > int(blockManager.bufferPoolSize) {
-		blockManager.EmptyBufferPool()
+		blockManager.emptyBufferPool()
 	}
 	return poolCapacity > int(blockManager.bufferPoolSize)
 }
 func (blockManager *BlockManager) EmptyBufferPool() {
+	blockManager.bufferPool.lock.Lock()
+	defer blockManager.bufferPool.lock.Unlock()
+	blockManager.emptyBufferPool()
+}
+
+// emptyBufferPool radi isto sto i EmptyBufferPool; pozivalac drzi lock buffer pool-a
+func (blockManager *BlockManager) emptyBufferPool() {
+	if blockManager.bufferPool.unsynced == nil {
+		blockManager.bufferPool.unsynced = make(map[string]bool)
+	}
 	for _, block := range blockManager.bufferPool.blocks {
+		// ostecen blok koji niko nije menjao ostaje u fajlu kakav jeste, da samo citanje ne bi odbacilo
+		// ostecen ostatak bloka; sta se sa njim radi odlucuje onaj ko cita (vidi walFile/recovery.go)
+		if _, corrupt := block.GetCorruption(); corrupt {
+			continue
+		}
 		blockManager.WriteBlock(block.records, block.blockFilePath, block.blockNumber)
+		blockManager.bufferPool.unsynced[block.blockFilePath] = true
 	}
 	blockManager.bufferPool.blocks = make([]*Block, 0)
 }
 
+// Sync prazni baferpul i radi fsync svakog fajla u koji je pisano od prethodnog Sync-a.
+// Fajl koji je u medjuvremenu obrisan (npr. stari segment WAL-a) se preskace.
+func (blockManager *BlockManager) Sync() error {
+	blockManager.bufferPool.lock.Lock()
+	defer blockManager.bufferPool.lock.Unlock()
+	blockManager.emptyBufferPool()
+	for fileName := range blockManager.bufferPool.unsynced {
+		file, err := os.OpenFile(fileName, os.O_RDWR, 0644)
+		if os.IsNotExist(err) {
+			delete(blockManager.bufferPool.unsynced, fileName)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to open %s for sync: %v", fileName, err)
+		}
+		err = file.Sync()
+		file.Close()
+		if err != nil {
+			return fmt.Errorf("failed to sync %s: %v", fileName, err)
+		}
+		delete(blockManager.bufferPool.unsynced, fileName)
+	}
+	return nil
+}
+
 func ReadHeader(fileName string) *Block {
 	blockManagerHeader := NewBlockManager(NewBufferPool(), 512, 512)
 	return blockManagerHeader.ReadBlockHeader(fileName, 0)
 }
 
 func (blockManager *BlockManager) ReadBlockHeader(fileName string, blockNum uint64) *Block {
-	block := blockManager.bufferPool.CheckForBlock(blockNum, fileName)
+	blockManager.bufferPool.lock.Lock()
+	defer blockManager.bufferPool.lock.Unlock()
+	block := blockManager.bufferPool.checkForBlock(blockNum, fileName)
 	if block != nil {
 		return block
 	}
@@ -233,7 +312,7 @@ func (blockManager *BlockManager) ReadBlockHeader(fileName string, blockNum uint
 		block.records = append(block.records, record)
 		start += int(record.recordSize)
 	}
-	blockManager.CheckPoolCapacity()
+	blockManager.checkPoolCapacity()
 	return block
 }
 
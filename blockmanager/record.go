@@ -1,7 +1,8 @@
 /*
 func Serialize(r *Record) []byte - funkcija za serijalizaciju rekorda vraca niz bajtova
 
-func Deserialize(blockData []byte) (*Record, uint8)- deserijalizacija rekorda, vraca rekord i porucu o gresci, 1 greska, 0 nema greske
+func Deserialize(blockData []byte) (*Record, uint8)- deserijalizacija rekorda, vraca rekord i porucu o gresci:
+0 nema greske, 1 nema vise rekorda (ostatak bloka je prazan), 2 rekord je ostecen (CRC se ne poklapa ili velicine nemaju smisla)
 
 func SetRec(tip uint16, lognum uint64, tbstn uint8, ks uint64, vs uint64, k string, v []byte) *Record - pravi rekord za zadate parametre
 
@@ -141,100 +142,58 @@ func Serialize(r *Record) []byte {
 	return data
 }
 
+// Rezultat Deserialize
+const (
+	DESERIALIZE_OK      = 0
+	DESERIALIZE_END     = 1 // na ovom mestu nema rekorda (nule do kraja bloka)
+	DESERIALIZE_CORRUPT = 2 // rekord je ostecen, npr. blok je samo delimicno upisan pre pada
+)
+
 func Deserialize(blockData []byte) (*Record, uint8) {
 	r := &Record{}
-	data := make([]byte, 0)
-	start := 0
-	if len(blockData) < start+4 {
-		return nil, 1
+	if len(blockData) < CRC_SIZE {
+		return nil, DESERIALIZE_END
 	}
-	crcByte := blockData[start : start+4]
-	start += 4
-
-	r.crcData = binary.LittleEndian.Uint32(crcByte)
+	r.crcData = binary.LittleEndian.Uint32(blockData)
 	if r.crcData == 0 {
-		return nil, 1
-	}
-
-	if len(blockData) < start+8 {
-		return nil, 1
+		return nil, DESERIALIZE_END
 	}
-	recordSizeByte := blockData[start : start+8]
-	start += 8
 
-	if len(blockData) < start+2 {
-		return nil, 1
+	// rekord je poceo, pa sve sto ne odgovara formatu znaci ostecen rekord
+	if len(blockData) < RECORD_BASE_SIZE {
+		return nil, DESERIALIZE_CORRUPT
 	}
-	recordTypeByte := blockData[start : start+2]
-	start += 2
-
-	if len(blockData) < start+8 {
-		return nil, 1
+	start := CRC_SIZE
+	r.recordSize = binary.LittleEndian.Uint64(blockData[start:])
+	start += REC_SIZE
+	r.recordType = binary.LittleEndian.Uint16(blockData[start:])
+	start += TYPE
+	r.logNum = binary.LittleEndian.Uint64(blockData[start:])
+	start += LOG_NUMBER
+	r.timeStamp = binary.LittleEndian.Uint64(blockData[start:])
+	start += TIMESTAMP_SIZE
+	r.tombstone = blockData[start]
+	start += TOMBSTONE_SIZE
+	r.keySize = binary.LittleEndian.Uint64(blockData[start:])
+	start += KEY_SIZE_SIZE
+	r.valueSize = binary.LittleEndian.Uint64(blockData[start:])
+	start += VALUE_SIZE_SIZE
+
+	available := uint64(len(blockData) - start)
+	if r.keySize > available || r.valueSize > available-r.keySize ||
+		r.recordSize != RECORD_BASE_SIZE+r.keySize+r.valueSize {
+		return nil, DESERIALIZE_CORRUPT
 	}
-	logNumByte := blockData[start : start+8]
-	start += 8
-
-	if len(blockData) < start+8 {
-		return nil, 1
-	}
-	timeStampByte := blockData[start : start+8]
-	start += 8
-
-	if len(blockData) < start+1 {
-		return nil, 1
-	}
-	tombstoneByte := blockData[start : start+1]
-	start += 1
-
-	if len(blockData) < start+8 {
-		return nil, 1
-	}
-	keySizeByte := blockData[start : start+8]
-	start += 8
-
-	if len(blockData) < start+8 {
-		return nil, 1
-	}
-	valueSizeByte := blockData[start : start+8]
-	start += 8
-
-	r.recordSize = binary.LittleEndian.Uint64(recordSizeByte)
-	r.logNum = binary.LittleEndian.Uint64(logNumByte)
-	r.recordType = binary.LittleEndian.Uint16(recordTypeByte)
-	r.timeStamp = binary.LittleEndian.Uint64(timeStampByte)
-	r.tombstone = tombstoneByte[0]
-	r.keySize = binary.LittleEndian.Uint64(keySizeByte)
-	r.valueSize = binary.LittleEndian.Uint64(valueSizeByte)
-
-	if len(blockData) < start+int(r.keySize) {
-		return nil, 1
-	}
-	keyByte := blockData[start : start+int(r.keySize)]
+	r.key = string(blockData[start : start+int(r.keySize)])
 	start += int(r.keySize)
-
-	if len(blockData) < start+int(r.valueSize) {
-		return nil, 1
-	}
-	valueByte := blockData[start : start+int(r.valueSize)]
+	r.value = blockData[start : start+int(r.valueSize)]
 	start += int(r.valueSize)
 
-	r.key = string(keyByte)
-	r.value = valueByte
-
-	data = append(data, recordSizeByte...)
-	data = append(data, recordTypeByte...)
-	data = append(data, logNumByte...)
-	data = append(data, timeStampByte...)
-	data = append(data, byte(r.tombstone))
-	data = append(data, keySizeByte...)
-	data = append(data, valueSizeByte...)
-	data = append(data, []byte(r.key)...)
-	data = append(data, r.value...)
-	if r.crcData != CRC32(data) {
-		panic("Greska crc se ne poklapa")
+	// CRC pokriva sve posle samog CRC-a
+	if r.crcData != CRC32(blockData[CRC_SIZE:start]) {
+		return nil, DESERIALIZE_CORRUPT
 	}
-
-	return r, 0
+	return r, DESERIALIZE_OK
 }
 
 func RecordsToByte(records []*Record) []byte {
@@ -305,15 +264,16 @@ func (record *Record) DivideRecord(blockSize uint64) []*Record {
 
 	i := 0
 	for i != numOfIterNewRecords {
-		if lenRecordValue == 0 {
-			records[len(records)-1].recordType = 3
-			break
-		}
 		if lenRecordValue/int(spaceLeftInBlockValue) < 1 {
 			point = uint64(lenRecordValue)
 			records = append(records, RecordPart(record, uint64(lenRecordValue), valueChunk[:point], 3))
 			break
 		}
+		// tip dela mora biti poznat pre RecordPart jer ulazi u CRC, pa se pun poslednji deo odmah pravi kao tip 3
+		if lenRecordValue == int(spaceLeftInBlockValue) {
+			records = append(records, RecordPart(record, spaceLeftInBlockValue, valueChunk[:point], 3))
+			break
+		}
 		if i == 0 {
 			records = append(records, RecordPart(record, spaceLeftInBlockValue, valueChunk[:point], 1))
 		} else {
@@ -375,3 +335,24 @@ func RecordPart(wholeRecord *Record, valueSize uint64, valueChunk []byte, part u
 
 	return r
 }
+
+// MergeRecordParts spaja delove podeljenog rekorda (first, middle..., last) u jedan FULL rekord.
+// Timestamp i logNum se preuzimaju iz prvog dela da se ne bi narusio redosled verzija.
+func MergeRecordParts(parts []*Record) *Record {
+	if len(parts) == 0 {
+		return nil
+	}
+	first := parts[0]
+	value := make([]byte, 0)
+	for _, part := range parts {
+		value = append(value, part.value...)
+	}
+	whole := &Record{
+		timeStamp: first.timeStamp,
+		logNum:    first.logNum,
+		tombstone: first.tombstone,
+		keySize:   first.keySize,
+		key:       first.key,
+	}
+	return RecordPart(whole, uint64(len(value)), value, 0)
+}
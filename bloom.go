@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"project/sstable"
+)
+
+// BloomCreate pravi prazan Bloom filter za ocekivani broj elemenata i verovatnocu laznog pozitivnog pod kljucem key
+func (manager *Manager) BloomCreate(key string, expectedElements int, falsePositiveRate float64) error {
+	if err := manager.takeToken(); err != nil {
+		return err
+	}
+	if expectedElements <= 0 {
+		return fmt.Errorf("bloom filter expected elements must be positive, got %d", expectedElements)
+	}
+	if falsePositiveRate <= 0 || falsePositiveRate >= 1 {
+		return fmt.Errorf("bloom filter false positive rate must be between 0 and 1, got %v", falsePositiveRate)
+	}
+	filter := sstable.NewBloomFilter(expectedElements, falsePositiveRate)
+	return manager.updateValue(key, func(value []byte) ([]byte, error) {
+		if value != nil {
+			return nil, fmt.Errorf("key %q already exists", key)
+		}
+		return encodeTyped(ValueBloom, filter.WriteBloomFilterFile()), nil
+	})
+}
+
+// BloomAdd dodaje element u Bloom filter sacuvan pod kljucem key
+func (manager *Manager) BloomAdd(key string, item []byte) error {
+	if err := manager.takeToken(); err != nil {
+		return err
+	}
+	return manager.updateValue(key, func(value []byte) ([]byte, error) {
+		filter, err := decodeBloom(key, value)
+		if err != nil {
+			return nil, err
+		}
+		filter.Add(item)
+		return encodeTyped(ValueBloom, filter.WriteBloomFilterFile()), nil
+	})
+}
+
+// BloomCheck proverava da li element mozda postoji u Bloom filteru pod kljucem key
+func (manager *Manager) BloomCheck(key string, item []byte) (bool, error) {
+	if err := manager.takeToken(); err != nil {
+		return false, err
+	}
+	value, err := manager.getValue(key)
+	if err != nil {
+		return false, err
+	}
+	filter, err := decodeBloom(key, value)
+	if err != nil {
+		return false, err
+	}
+	return filter.Contains(item), nil
+}
+
+// BloomDelete brise Bloom filter sacuvan pod kljucem key
+func (manager *Manager) BloomDelete(key string) error {
+	if err := manager.takeToken(); err != nil {
+		return err
+	}
+	return manager.updateValue(key, func(value []byte) ([]byte, error) {
+		if _, err := decodeTyped(ValueBloom, key, value); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	})
+}
+
+// decodeBloom deserijalizuje sacuvanu vrednost; nil znaci da filter ne postoji
+func decodeBloom(key string, value []byte) (*sstable.BloomFilter, error) {
+	data, err := decodeTyped(ValueBloom, key, value)
+	if err != nil {
+		return nil, err
+	}
+	filter, err := sstable.ReadBloomFilter(data)
+	if err != nil {
+		return nil, fmt.Errorf("bloom filter %q is corrupted: %v", key, err)
+	}
+	return filter, nil
+}
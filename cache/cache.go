@@ -3,6 +3,7 @@ package cache
 import (
 	"container/list"
 	"project/blockmanager"
+	"sync"
 )
 
 type entry struct {
@@ -10,10 +11,13 @@ type entry struct {
 	value *blockmanager.Record
 }
 
+// Cache je LRU kes rekorda; bezbedan je za istovremeno koriscenje iz vise gorutina
+// (i Get menja redosled u listi, pa se koristi obican Mutex)
 type Cache struct {
 	list     *list.List
 	table    map[string]*list.Element
 	capacity int
+	lock     sync.Mutex
 }
 
 func NewCache(capacity int) *Cache {
@@ -25,6 +29,8 @@ func NewCache(capacity int) *Cache {
 }
 
 func (c *Cache) Put(record *blockmanager.Record) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
 	elem, ok := c.table[record.GetKey()]
 	if ok {
 		elem.Value.(*entry).value = record
@@ -42,6 +48,8 @@ func (c *Cache) Put(record *blockmanager.Record) {
 }
 
 func (c *Cache) Get(key string) (*blockmanager.Record, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
 	elem, ok := c.table[key]
 	if ok {
 		c.list.MoveToBack(elem)
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"project/countminsketch"
+)
+
+// CMSCreate pravi prazan Count-Min Sketch sa greskom epsilon i verovatnocom greske delta pod kljucem key
+func (manager *Manager) CMSCreate(key string, epsilon float64, delta float64) error {
+	if err := manager.takeToken(); err != nil {
+		return err
+	}
+	cms, err := countminsketch.NewCountMinSketch(epsilon, delta)
+	if err != nil {
+		return err
+	}
+	return manager.updateValue(key, func(value []byte) ([]byte, error) {
+		if value != nil {
+			return nil, fmt.Errorf("key %q already exists", key)
+		}
+		return encodeTyped(ValueCMS, cms.Serialize()), nil
+	})
+}
+
+// CMSAdd povecava ucestalost elementa za count u sketch-u pod kljucem key
+func (manager *Manager) CMSAdd(key string, item []byte, count uint64) error {
+	if err := manager.takeToken(); err != nil {
+		return err
+	}
+	return manager.updateValue(key, func(value []byte) ([]byte, error) {
+		cms, err := decodeCMS(key, value)
+		if err != nil {
+			return nil, err
+		}
+		cms.Add(item, count)
+		return encodeTyped(ValueCMS, cms.Serialize()), nil
+	})
+}
+
+// CMSQuery vraca procenu ucestalosti elementa u sketch-u pod kljucem key
+func (manager *Manager) CMSQuery(key string, item []byte) (uint64, error) {
+	if err := manager.takeToken(); err != nil {
+		return 0, err
+	}
+	value, err := manager.getValue(key)
+	if err != nil {
+		return 0, err
+	}
+	cms, err := decodeCMS(key, value)
+	if err != nil {
+		return 0, err
+	}
+	return cms.Count(item), nil
+}
+
+// CMSDelete brise sketch sacuvan pod kljucem key
+func (manager *Manager) CMSDelete(key string) error {
+	if err := manager.takeToken(); err != nil {
+		return err
+	}
+	return manager.updateValue(key, func(value []byte) ([]byte, error) {
+		if _, err := decodeTyped(ValueCMS, key, value); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	})
+}
+
+// decodeCMS deserijalizuje sacuvanu vrednost; nil znaci da sketch ne postoji
+func decodeCMS(key string, value []byte) (*countminsketch.CountMinSketch, error) {
+	data, err := decodeTyped(ValueCMS, key, value)
+	if err != nil {
+		return nil, err
+	}
+	cms, err := countminsketch.Deserialize(data)
+	if err != nil {
+		return nil, fmt.Errorf("cms %q is corrupted: %v", key, err)
+	}
+	return cms, nil
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"project/sstable"
+)
+
+// Granice velicine za size-tiered: tabela pripada grupi ako je njena velicina
+// izmedju bucketLow i bucketHigh puta prosecne velicine grupe.
+const (
+	sizeTieredBucketLow  = 0.5
+	sizeTieredBucketHigh = 1.5
+)
+
+// compact pokrece kompakciju po strategiji iz configa
+func (manager *Manager) compact() error {
+	if conf.CompactionStrategy == CompactionLeveled {
+		return manager.compactLeveled()
+	}
+	return manager.compactSizeTiered()
+}
+
+// compactSizeTiered pokrece kompakciju sve dok na nivou 0 postoji grupa tabela koja ispunjava uslov
+func (manager *Manager) compactSizeTiered() error {
+	for {
+		run, err := manager.pickSizeTieredRun()
+		if err != nil {
+			return err
+		}
+		if run == nil {
+			return nil
+		}
+		if err := manager.compactTables(run); err != nil {
+			return err
+		}
+	}
+}
+
+// pickSizeTieredRun trazi najstariju grupu uzastopnih tabela slicne velicine koja ima bar
+// CompactionMinThreshold tabela. Grupe moraju biti uzastopne da bi rezultat mogao da zauzme
+// njihovo mesto u redosledu generacija bez zaklanjanja novijih verzija kljuceva.
+func (manager *Manager) pickSizeTieredRun() ([]*sstable.SSTable, error) {
+	var bucket []*sstable.SSTable
+	var total int64
+	for _, table := range manager.tables.GetLevel(0) {
+		size, err := table.GetSize()
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat sstable %d: %v", table.GetID(), err)
+		}
+		if len(bucket) > 0 {
+			avg := float64(total) / float64(len(bucket))
+			if float64(size) < avg*sizeTieredBucketLow || float64(size) > avg*sizeTieredBucketHigh {
+				bucket, total = nil, 0
+			}
+		}
+		bucket = append(bucket, table)
+		total += size
+		if len(bucket) >= conf.CompactionMinThreshold {
+			return bucket, nil
+		}
+	}
+	return nil, nil
+}
+
+// compactTables spaja uzastopne tabele nivoa 0 u novu generaciju, menja ih u registru i brise ulazne fajlove
+func (manager *Manager) compactTables(inputs []*sstable.SSTable) error {
+	// tombstone-ovi se smeju izbaciti samo ako ispod ulaza nema starijih tabela
+	dropTombstones := manager.tables.GetLevel(0)[0] == inputs[0] && manager.tables.Len() == len(manager.tables.GetLevel(0))
+	records, err := sstable.MergeTables(inputs, dropTombstones)
+	if err != nil {
+		return fmt.Errorf("failed to merge sstables: %v", err)
+	}
+
+	var output *sstable.SSTable
+	if len(records) > 0 {
+		output, err = sstable.CreateSSTable(manager.mfile.sstableID, manager.mfile.nextTableFiles(), records, conf.BlockSize, conf.SummaryStep)
+		if err != nil {
+			return fmt.Errorf("failed to write compacted sstable: %v", err)
+		}
+		manager.mfile.sstableID += 1
+	}
+
+	// manifest je tacka zamene: do ovde su ulazi i dalje jedine zive tabele
+	// (citaoci drze lock dok citaju tabele, pa posle zamene niko ne cita ulaze koji se brisu)
+	manager.lock.Lock()
+	err = manager.tables.Replace(inputs, output)
+	manager.lock.Unlock()
+	if err != nil {
+		return err
+	}
+
+	ids := make([]int, 0, len(inputs))
+	for _, table := range inputs {
+		ids = append(ids, table.GetID())
+		if err := table.Remove(); err != nil {
+			return err
+		}
+	}
+	if output != nil {
+		fmt.Printf("Compacted SSTables %v into SSTable %d\n", ids, output.GetID())
+	} else {
+		fmt.Printf("Compacted SSTables %v, no live records left\n", ids)
+	}
+	return nil
+}
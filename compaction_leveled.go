@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"project/sstable"
+)
+
+// levelMaxTables vraca najveci dozvoljeni broj tabela na nivou >= 1:
+// nivo 1 ima Level1MaxTables, a svaki sledeci LevelFanout puta vise
+func levelMaxTables(level int) int {
+	limit := conf.Level1MaxTables
+	for i := 1; i < level; i++ {
+		limit *= conf.LevelFanout
+	}
+	return limit
+}
+
+// leveledTableRecords je broj rekorda u jednoj izlaznoj tabeli, koliko ima i jedna flush-ovana tabela memtable-a
+func leveledTableRecords() int {
+	return conf.MemCapacity
+}
+
+// compactLeveled pokrece kompakcije sve dok neki nivo prelazi svoj limit
+func (manager *Manager) compactLeveled() error {
+	for {
+		compacted, err := manager.compactLeveledStep()
+		if err != nil || !compacted {
+			return err
+		}
+	}
+}
+
+// compactLeveledStep radi jednu kompakciju ako je potrebna; vraca false ako su svi nivoi u limitu
+func (manager *Manager) compactLeveledStep() (bool, error) {
+	// nivo 0: opsezi se preklapaju, pa se sve tabele spustaju zajedno
+	level0 := manager.tables.GetLevel(0)
+	if len(level0) >= conf.Level0MaxTables {
+		return true, manager.compactIntoLevel(level0, 1)
+	}
+
+	// poslednji nivo nema limit
+	for level := 1; level < conf.MaxLevels-1; level++ {
+		tables := manager.tables.GetLevel(level)
+		if len(tables) <= levelMaxTables(level) {
+			continue
+		}
+		// spusta se najstarija tabela nivoa
+		oldest := tables[0]
+		for _, t := range tables {
+			if t.GetID() < oldest.GetID() {
+				oldest = t
+			}
+		}
+		return true, manager.compactIntoLevel([]*sstable.SSTable{oldest}, level+1)
+	}
+	return false, nil
+}
+
+// compactIntoLevel spaja tabele sa viseg nivoa sa tabelama ciljnog nivoa ciji se opsezi preklapaju,
+// a rezultat deli na tabele od po leveledTableRecords rekorda koje ne preklapaju ostatak ciljnog nivoa.
+func (manager *Manager) compactIntoLevel(upper []*sstable.SSTable, target int) error {
+	minKey, maxKey := upper[0].GetMinKey(), upper[0].GetMaxKey()
+	for _, t := range upper[1:] {
+		if t.GetMinKey() < minKey {
+			minKey = t.GetMinKey()
+		}
+		if t.GetMaxKey() > maxKey {
+			maxKey = t.GetMaxKey()
+		}
+	}
+
+	// tabele ciljnog nivoa su starije od svih sa viseg nivoa, pa idu prve u merge
+	inputs := make([]*sstable.SSTable, 0)
+	for _, t := range manager.tables.GetLevel(target) {
+		if t.Overlaps(minKey, maxKey) {
+			inputs = append(inputs, t)
+		}
+	}
+	inputs = append(inputs, upper...)
+
+	// tombstone-ovi se smeju izbaciti samo ako ispod ciljnog nivoa nema tabela
+	dropTombstones := true
+	for level := target + 1; level < manager.tables.NumLevels(); level++ {
+		if len(manager.tables.GetLevel(level)) > 0 {
+			dropTombstones = false
+		}
+	}
+
+	records, err := sstable.MergeTables(inputs, dropTombstones)
+	if err != nil {
+		return fmt.Errorf("failed to merge sstables: %v", err)
+	}
+
+	outputs := make([]*sstable.SSTable, 0)
+	chunkSize := leveledTableRecords()
+	for start := 0; start < len(records); start += chunkSize {
+		end := min(start+chunkSize, len(records))
+		output, err := sstable.CreateSSTable(manager.mfile.sstableID, manager.mfile.nextTableFiles(), records[start:end], conf.BlockSize, conf.SummaryStep)
+		if err != nil {
+			return fmt.Errorf("failed to write compacted sstable: %v", err)
+		}
+		manager.mfile.sstableID += 1
+		outputs = append(outputs, output)
+	}
+
+	// manifest je tacka zamene: do ovde su ulazi i dalje jedine zive tabele
+	manager.lock.Lock()
+	err = manager.tables.ReplaceLevel(inputs, outputs, target)
+	manager.lock.Unlock()
+	if err != nil {
+		return err
+	}
+
+	inputIDs := make([]int, 0, len(inputs))
+	for _, table := range inputs {
+		inputIDs = append(inputIDs, table.GetID())
+		if err := table.Remove(); err != nil {
+			return err
+		}
+	}
+	outputIDs := make([]int, 0, len(outputs))
+	for _, table := range outputs {
+		outputIDs = append(outputIDs, table.GetID())
+	}
+	fmt.Printf("Compacted SSTables %v into level %d as %v\n", inputIDs, target, outputIDs)
+	return nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"project/memtable"
+	"testing"
+)
+
+func TestLeveledCompactionSplitsOutput(t *testing.T) {
+	useTempDir(t)
+	conf.RateLimitCapacity = 1 << 30
+	conf.MemCapacity = 5
+	conf.CompactionStrategy = CompactionLeveled
+	conf.Level0MaxTables = 2
+	conf.Level1MaxTables = 100
+	m := NewManager(memtable.TypeSkipList)
+
+	const keys = 48
+	for i := 0; i < keys; i++ {
+		if err := m.PUT(fmt.Sprintf("key-%03d", i), []byte(fmt.Sprintf("value-%d", i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Close ceka da flusher zavrsi sve flush-eve i kompakcije
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	level1 := m.tables.GetLevel(1)
+	if len(level1) < 2 {
+		t.Fatalf("level 1 has %d tables, want the merged records split into several", len(level1))
+	}
+	if len(m.tables.GetLevel(0)) >= conf.Level0MaxTables {
+		t.Fatalf("level 0 still has %d tables", len(m.tables.GetLevel(0)))
+	}
+	for i, table := range level1 {
+		records, err := table.GetData().ReadAllRecords()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(records) == 0 || len(records) > leveledTableRecords() {
+			t.Fatalf("level 1 table %d has %d records, want 1 to %d", table.GetID(), len(records), leveledTableRecords())
+		}
+		// tabele nivoa su sortirane i ne preklapaju se
+		if i > 0 && level1[i-1].GetMaxKey() >= table.GetMinKey() {
+			t.Fatalf("level 1 tables %d and %d overlap", level1[i-1].GetID(), table.GetID())
+		}
+	}
+
+	m = NewManager(memtable.TypeSkipList)
+	defer m.Close()
+	for i := 0; i < keys; i++ {
+		key := fmt.Sprintf("key-%03d", i)
+		value, err := m.GET(key)
+		if err != nil || string(value) != fmt.Sprintf("value-%d", i) {
+			t.Fatalf("GET %s = %q, %v", key, value, err)
+		}
+	}
+}
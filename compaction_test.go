@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"project/blockmanager"
+	"project/sstable"
+	"testing"
+)
+
+// stampedRecord pravi rekord sa zadatim timestamp-om, kao da je upisan dok je sat bio pomeren
+func stampedRecord(key, value string, tombstone uint8, timeStamp uint64) *blockmanager.Record {
+	record := blockmanager.SetRec(0, 0, tombstone, uint64(len(key)), uint64(len(value)), key, []byte(value))
+	record.SetTimeStamp(timeStamp)
+	record.SetCRCData(blockmanager.CRC32(blockmanager.Serialize(record)[blockmanager.CRC_SIZE:]))
+	return record
+}
+
+// addTestTable upisuje rekorde u novu generaciju SSTable-a i registruje je kao najnoviju na nivou 0
+func addTestTable(t *testing.T, m *Manager, records ...*blockmanager.Record) {
+	t.Helper()
+	table, err := sstable.CreateSSTable(m.mfile.sstableID, m.mfile.nextTableFiles(), records, conf.BlockSize, conf.SummaryStep)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.mfile.sstableID += 1
+	if err := m.tables.Add(table); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// scanAll vraca zive kljuceve i vrednosti iz celog opsega kao "kljuc=vrednost"
+func scanAll(t *testing.T, m *Manager) []string {
+	t.Helper()
+	iterator, err := m.RangeIterate("", "~")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer iterator.Stop()
+	got := make([]string, 0)
+	for {
+		record, ok := iterator.Next()
+		if !ok {
+			break
+		}
+		got = append(got, record.GetKey()+"="+string(record.GetValue()))
+	}
+	if err := iterator.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return got
+}
+
+// TestNewestTableWinsOverTimestamp proverava da GET, skeniranje i kompakcija biraju istu verziju kljuca
+// kada novija tabela ima starije timestamp-ove (sat je vracen unazad izmedju dva flush-a)
+func TestNewestTableWinsOverTimestamp(t *testing.T) {
+	m := openTestManager(t)
+	defer m.Close()
+	addTestTable(t, m,
+		stampedRecord("a", "old", 0, 200),
+		stampedRecord("b", "old", 0, 200),
+		stampedRecord("c", "old", 0, 200),
+	)
+	addTestTable(t, m,
+		stampedRecord("a", "new", 0, 100),
+		stampedRecord("b", "", 1, 100),
+		stampedRecord("d", "new", 0, 100),
+	)
+
+	want := map[string]string{"a": "new", "b": "", "c": "old", "d": "new"}
+	check := func(stage string) {
+		for key, value := range want {
+			got, err := m.GET(key)
+			if err != nil {
+				t.Fatalf("%s: GET %s: %v", stage, key, err)
+			}
+			if string(got) != value {
+				t.Fatalf("%s: GET %s = %q, want %q", stage, key, got, value)
+			}
+		}
+		if got := fmt.Sprint(scanAll(t, m)); got != "[a=new c=old d=new]" {
+			t.Fatalf("%s: scan = %s, want [a=new c=old d=new]", stage, got)
+		}
+	}
+	check("before compaction")
+
+	// ispod ulaza nema starijih tabela, pa kompakcija izbacuje i tombstone
+	if err := m.compactTables(m.tables.GetLevel(0)); err != nil {
+		t.Fatal(err)
+	}
+	if m.tables.Len() != 1 {
+		t.Fatalf("%d tables after compaction, want 1", m.tables.Len())
+	}
+	records, err := m.tables.GetLevel(0)[0].GetData().ReadAllRecords()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("compacted table has %d records, want 3 without the tombstone", len(records))
+	}
+	check("after compaction")
+}
@@ -2,7 +2,17 @@ package main
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
+	"project/blockmanager"
+	"project/memtable"
+	wal "project/walFile"
+)
+
+// Podrzane strategije kompakcije
+const (
+	CompactionSizeTiered = "size-tiered"
+	CompactionLeveled    = "leveled"
 )
 
 type Config struct {
@@ -10,6 +20,54 @@ type Config struct {
 	MemCapacity   int    `json:"memCapacity"`
 	SummaryStep   int    `json:"summaryStep"`
 	CacheCapacity int    `json:"cacheCapacity"`
+
+	// koren direktorijuma sa podacima: WAL je u dataDir/walFile, a SSTable-ovi u dataDir/sstable
+	DataDir string `json:"dataDir"`
+
+	// broj blokova u jednom segmentu WAL-a
+	WalSegmentSize int `json:"walSegmentSize"`
+
+	// kada se WAL fsync-uje: "always" (pre potvrde svakog upisa), "interval" (u pozadini na svakih
+	// walSyncInterval milisekundi) ili "none" (samo pri zatvaranju); vidi walFile/sync.go
+	WalSyncMode     string `json:"walSyncMode"`
+	WalSyncInterval int    `json:"walSyncInterval"`
+
+	// group commit u "always" rezimu: koliko mikrosekundi prvi pisac najduze ceka ostale
+	// i posle koliko rekorda u grupi ne ceka dalje, vec odmah radi fsync
+	WalGroupCommitDelay int `json:"walGroupCommitDelay"`
+	WalGroupCommitSize  int `json:"walGroupCommitSize"`
+
+	// sta se pri pokretanju radi sa ostecenim rekordom u WAL-u (npr. posle pada usred upisa): "truncate" (WAL se
+	// odseca na prvom ostecenju), "skip" (preskace se osteceni deo) ili "fail" (greska); vidi walFile/recovery.go
+	WalRecoveryMode string `json:"walRecoveryMode"`
+
+	// "skiplist", "hashmap" ili "btree"; ako je prazno, tip se bira pri pokretanju
+	MemtableType string `json:"memtableType"`
+	// broj tabela memtable-a (pune se jedna po jedna, a kada su sve pune sve idu na flush), visina skip liste
+	// i minimalni stepen B-stabla
+	MemtableTables int `json:"memtableTables"`
+	SkipListHeight int `json:"skipListHeight"`
+	BTreeMinDegree int `json:"btreeMinDegree"`
+
+	// nove SSTable generacije se pisu u jedan fajl (dataDir/sstable/TABLE) umesto u pet odvojenih;
+	// postojece tabele se citaju u formatu u kom su zapisane
+	SingleFileSSTable bool `json:"singleFileSSTable"`
+
+	// "size-tiered" ili "leveled"
+	CompactionStrategy string `json:"compactionStrategy"`
+
+	// broj uzastopnih tabela slicne velicine koje pokrecu size-tiered kompakciju
+	CompactionMinThreshold int `json:"compactionMinThreshold"`
+
+	// leveled: broj nivoa, broj tabela na nivou 0 i 1, i koliko puta je svaki sledeci nivo veci
+	MaxLevels       int `json:"maxLevels"`
+	Level0MaxTables int `json:"level0MaxTables"`
+	Level1MaxTables int `json:"level1MaxTables"`
+	LevelFanout     int `json:"levelFanout"`
+
+	// token bucket: najvise rateLimitCapacity zahteva (PUT/GET/DELETE) na svakih rateLimitInterval sekundi
+	RateLimitCapacity int `json:"rateLimitCapacity"`
+	RateLimitInterval int `json:"rateLimitInterval"`
 }
 
 func LoadConfig(path string) (*Config, error) {
@@ -24,6 +82,27 @@ func LoadConfig(path string) (*Config, error) {
 		MemCapacity:   2,
 		SummaryStep:   2,
 		CacheCapacity: 5,
+
+		DataDir:             ".",
+		WalSegmentSize:      5,
+		WalSyncMode:         string(wal.SYNC_INTERVAL),
+		WalSyncInterval:     100,
+		WalGroupCommitDelay: 500,
+		WalGroupCommitSize:  64,
+		WalRecoveryMode:     string(wal.RECOVERY_TRUNCATE),
+		MemtableTables:      memtable.DEFAULT_NUMBER_OF_TABLES,
+		SkipListHeight:      memtable.DEFAULT_SKIP_LIST_HEIGHT,
+		BTreeMinDegree:      memtable.DEFAULT_BTREE_MIN_DEGREE,
+
+		CompactionStrategy:     CompactionSizeTiered,
+		CompactionMinThreshold: 4,
+		MaxLevels:              4,
+		Level0MaxTables:        4,
+		Level1MaxTables:        4,
+		LevelFanout:            10,
+
+		RateLimitCapacity: 100,
+		RateLimitInterval: 60,
 	}
 
 	// zatim prepiši vrednosti iz JSON-a (ako postoje)
@@ -31,19 +110,87 @@ func LoadConfig(path string) (*Config, error) {
 		return nil, err
 	}
 
-	// validacija – ako je neko uneo glupost (npr. 0 ili negativno)
-	if cfg.BlockSize <= 0 {
-		cfg.BlockSize = 4096
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %v", path, err)
+	}
+	return cfg, nil
+}
+
+// validate vraca gresku za prvu besmislenu vrednost (npr. 0 ili negativno), umesto da je tiho zameni default-om
+func (cfg *Config) validate() error {
+	// blok mora da primi bar jedan rekord sa kljucem od jednog bajta (duze kljuceve odbija checkKeySize)
+	minBlockSize := uint64(blockmanager.RECORD_BASE_SIZE + 1)
+	if cfg.BlockSize < minBlockSize {
+		return fmt.Errorf("blockSize must be at least %d (one record with a one-byte key), got %d", minBlockSize, cfg.BlockSize)
 	}
 	if cfg.MemCapacity <= 0 {
-		cfg.MemCapacity = 2
+		return fmt.Errorf("memCapacity must be positive, got %d", cfg.MemCapacity)
 	}
 	if cfg.SummaryStep <= 0 {
-		cfg.SummaryStep = 2
+		return fmt.Errorf("summaryStep must be positive, got %d", cfg.SummaryStep)
 	}
 	if cfg.CacheCapacity <= 0 {
-		cfg.CacheCapacity = 5
+		return fmt.Errorf("cacheCapacity must be positive, got %d", cfg.CacheCapacity)
 	}
-
-	return cfg, nil
+	if cfg.DataDir == "" {
+		return fmt.Errorf("dataDir must not be empty")
+	}
+	if cfg.WalSegmentSize <= 0 {
+		return fmt.Errorf("walSegmentSize must be positive, got %d", cfg.WalSegmentSize)
+	}
+	if _, err := wal.ParseSyncMode(cfg.WalSyncMode); err != nil {
+		return err
+	}
+	if cfg.WalSyncMode == string(wal.SYNC_INTERVAL) && cfg.WalSyncInterval <= 0 {
+		return fmt.Errorf("walSyncInterval must be positive, got %d", cfg.WalSyncInterval)
+	}
+	if cfg.WalGroupCommitDelay < 0 {
+		return fmt.Errorf("walGroupCommitDelay must not be negative, got %d", cfg.WalGroupCommitDelay)
+	}
+	if cfg.WalGroupCommitSize <= 0 {
+		return fmt.Errorf("walGroupCommitSize must be positive, got %d", cfg.WalGroupCommitSize)
+	}
+	if _, err := wal.ParseRecoveryMode(cfg.WalRecoveryMode); err != nil {
+		return err
+	}
+	if cfg.MemtableType != "" {
+		if _, err := memtable.ParseMemTableType(cfg.MemtableType); err != nil {
+			return err
+		}
+	}
+	if cfg.MemtableTables <= 0 {
+		return fmt.Errorf("memtableTables must be positive, got %d", cfg.MemtableTables)
+	}
+	if cfg.SkipListHeight <= 0 {
+		return fmt.Errorf("skipListHeight must be positive, got %d", cfg.SkipListHeight)
+	}
+	if cfg.BTreeMinDegree < 2 {
+		return fmt.Errorf("btreeMinDegree must be at least 2, got %d", cfg.BTreeMinDegree)
+	}
+	if cfg.CompactionStrategy != CompactionSizeTiered && cfg.CompactionStrategy != CompactionLeveled {
+		return fmt.Errorf("unknown compactionStrategy %q (expected %q or %q)",
+			cfg.CompactionStrategy, CompactionSizeTiered, CompactionLeveled)
+	}
+	if cfg.CompactionMinThreshold < 2 {
+		return fmt.Errorf("compactionMinThreshold must be at least 2, got %d", cfg.CompactionMinThreshold)
+	}
+	if cfg.MaxLevels < 2 {
+		return fmt.Errorf("maxLevels must be at least 2, got %d", cfg.MaxLevels)
+	}
+	if cfg.Level0MaxTables <= 0 {
+		return fmt.Errorf("level0MaxTables must be positive, got %d", cfg.Level0MaxTables)
+	}
+	if cfg.Level1MaxTables <= 0 {
+		return fmt.Errorf("level1MaxTables must be positive, got %d", cfg.Level1MaxTables)
+	}
+	if cfg.LevelFanout < 2 {
+		return fmt.Errorf("levelFanout must be at least 2, got %d", cfg.LevelFanout)
+	}
+	if cfg.RateLimitCapacity <= 0 {
+		return fmt.Errorf("rateLimitCapacity must be positive, got %d", cfg.RateLimitCapacity)
+	}
+	if cfg.RateLimitInterval <= 0 {
+		return fmt.Errorf("rateLimitInterval must be positive, got %d", cfg.RateLimitInterval)
+	}
+	return nil
 }
@@ -0,0 +1,127 @@
+package countminsketch
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"project/hashfunc"
+)
+
+// CalculateM izracunava sirinu tabele (broj kolona) za dozvoljenu gresku epsilon
+func CalculateM(epsilon float64) uint {
+	return uint(math.Ceil(math.E / epsilon))
+}
+
+// CalculateK izracunava dubinu tabele (broj hash funkcija) za verovatnocu greske delta
+func CalculateK(delta float64) uint {
+	return uint(math.Ceil(math.Log(1 / delta)))
+}
+
+// CountMinSketch procenjuje ucestalost elemenata. Svaki red ima svoju hash funkciju,
+// a procena je najmanji brojac elementa po svim redovima (nikad nije manja od tacne vrednosti).
+type CountMinSketch struct {
+	HashFunctions []hashfunc.HashWithSeed
+	table         [][]uint64
+	m             uint // sirina (broj kolona)
+	k             uint // dubina (broj redova i hash funkcija)
+}
+
+// NewCountMinSketch pravi prazan sketch: greska procene je najvise epsilon*N sa verovatnocom 1-delta,
+// gde je N ukupan broj dodatih elemenata
+func NewCountMinSketch(epsilon float64, delta float64) (*CountMinSketch, error) {
+	if epsilon <= 0 || epsilon >= 1 {
+		return nil, fmt.Errorf("cms epsilon must be between 0 and 1, got %v", epsilon)
+	}
+	if delta <= 0 || delta >= 1 {
+		return nil, fmt.Errorf("cms delta must be between 0 and 1, got %v", delta)
+	}
+	m := CalculateM(epsilon)
+	k := CalculateK(delta)
+	return newCountMinSketch(m, k, hashfunc.CreateHashFunctions(uint32(k))), nil
+}
+
+func newCountMinSketch(m uint, k uint, hashFunctions []hashfunc.HashWithSeed) *CountMinSketch {
+	table := make([][]uint64, k)
+	for i := range table {
+		table[i] = make([]uint64, m)
+	}
+	return &CountMinSketch{
+		HashFunctions: hashFunctions,
+		table:         table,
+		m:             m,
+		k:             k,
+	}
+}
+
+// Getteri
+func (cms *CountMinSketch) GetM() uint { return cms.m }
+func (cms *CountMinSketch) GetK() uint { return cms.k }
+
+// Add povecava ucestalost elementa za count
+func (cms *CountMinSketch) Add(data []byte, count uint64) {
+	for i, fn := range cms.HashFunctions {
+		j := fn.Hash(data) % uint64(cms.m)
+		cms.table[i][j] += count
+	}
+}
+
+// Count vraca procenu ucestalosti elementa
+func (cms *CountMinSketch) Count(data []byte) uint64 {
+	result := uint64(math.MaxUint64)
+	for i, fn := range cms.HashFunctions {
+		j := fn.Hash(data) % uint64(cms.m)
+		result = min(result, cms.table[i][j])
+	}
+	return result
+}
+
+// Serialize vraca sketch kao niz bajtova: m i k (uint64), brojaci red po red (uint64),
+// pa seed-ovi hash funkcija (po 4 bajta). Sve je little endian.
+func (cms *CountMinSketch) Serialize() []byte {
+	data := make([]byte, 0, 16+8*cms.m*cms.k+4*cms.k)
+	data = binary.LittleEndian.AppendUint64(data, uint64(cms.m))
+	data = binary.LittleEndian.AppendUint64(data, uint64(cms.k))
+	for _, row := range cms.table {
+		for _, counter := range row {
+			data = binary.LittleEndian.AppendUint64(data, counter)
+		}
+	}
+	for _, fn := range cms.HashFunctions {
+		data = append(data, fn.Seed...)
+	}
+	return data
+}
+
+// Deserialize cita sketch zapisan sa Serialize
+func Deserialize(data []byte) (*CountMinSketch, error) {
+	if len(data) < 16 {
+		return nil, fmt.Errorf("cms data is too short")
+	}
+	m := binary.LittleEndian.Uint64(data)
+	k := binary.LittleEndian.Uint64(data[8:])
+	if m == 0 || k == 0 {
+		return nil, fmt.Errorf("cms has no counters")
+	}
+	// m se ogranicava duzinom podataka pre mnozenja, a k deljenjem, da pokvareni m i k ne bi prekoracili uint64
+	rest := uint64(len(data) - 16)
+	if m > rest/8 || rest%(8*m+4) != 0 || rest/(8*m+4) != k {
+		return nil, fmt.Errorf("cms data has %d bytes, which does not match width %d and depth %d", len(data), m, k)
+	}
+
+	hashFunctions := make([]hashfunc.HashWithSeed, k)
+	seeds := data[16+8*m*k:]
+	for i := range hashFunctions {
+		seed := make([]byte, 4)
+		copy(seed, seeds[4*i:])
+		hashFunctions[i] = hashfunc.HashWithSeed{Seed: seed}
+	}
+	cms := newCountMinSketch(uint(m), uint(k), hashFunctions)
+	offset := uint64(16)
+	for _, row := range cms.table {
+		for j := range row {
+			row[j] = binary.LittleEndian.Uint64(data[offset:])
+			offset += 8
+		}
+	}
+	return cms, nil
+}
@@ -0,0 +1,80 @@
+package countminsketch
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+func TestDimensionsFromEpsilonAndDelta(t *testing.T) {
+	cms, err := NewCountMinSketch(0.01, 0.01)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// sirina je ceil(e/epsilon), dubina ceil(ln(1/delta))
+	if cms.GetM() != 272 || cms.GetK() != 5 {
+		t.Fatalf("width %d and depth %d, want 272 and 5", cms.GetM(), cms.GetK())
+	}
+	for _, bad := range [][2]float64{{0, 0.1}, {1, 0.1}, {0.1, 0}, {0.1, 1}} {
+		if _, err := NewCountMinSketch(bad[0], bad[1]); err == nil {
+			t.Fatalf("NewCountMinSketch(%v, %v) succeeded", bad[0], bad[1])
+		}
+	}
+}
+
+func TestSerializeRoundTrip(t *testing.T) {
+	cms, err := NewCountMinSketch(0.1, 0.1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cms.Add([]byte("a"), 3)
+	cms.Add([]byte("b"), 1)
+	cms.Add([]byte("a"), 2)
+
+	restored, err := Deserialize(cms.Serialize())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.GetM() != cms.GetM() || restored.GetK() != cms.GetK() {
+		t.Fatalf("restored sketch is %dx%d, want %dx%d", restored.GetM(), restored.GetK(), cms.GetM(), cms.GetK())
+	}
+	// procena nikad nije manja od tacne vrednosti, a ista je kao pre serijalizacije
+	for _, key := range []string{"a", "b", "c"} {
+		if got, want := restored.Count([]byte(key)), cms.Count([]byte(key)); got != want {
+			t.Fatalf("Count(%s) = %d after round trip, want %d", key, got, want)
+		}
+	}
+	if restored.Count([]byte("a")) < 5 {
+		t.Fatalf("Count(a) = %d, want at least 5", restored.Count([]byte("a")))
+	}
+}
+
+func TestDeserializeCorrupted(t *testing.T) {
+	cms, err := NewCountMinSketch(0.1, 0.1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := cms.Serialize()
+
+	header := func(m, k uint64) []byte {
+		corrupted := append([]byte(nil), data...)
+		binary.LittleEndian.PutUint64(corrupted, m)
+		binary.LittleEndian.PutUint64(corrupted[8:], k)
+		return corrupted
+	}
+	cases := map[string][]byte{
+		"short":     data[:10],
+		"truncated": data[:len(data)-1],
+		"no width":  header(0, uint64(cms.GetK())),
+		"no depth":  header(uint64(cms.GetM()), 0),
+		"deeper":    header(uint64(cms.GetM()), uint64(cms.GetK())+1),
+		// 8*m+4 bi prekoracio uint64 i dao istu vrednost kao ispravna sirina
+		"huge width": header(uint64(cms.GetM())+1<<61, uint64(cms.GetK())),
+		"huge depth": header(uint64(cms.GetM()), math.MaxUint64),
+	}
+	for name, corrupted := range cases {
+		if _, err := Deserialize(corrupted); err == nil {
+			t.Errorf("%s: Deserialize succeeded", name)
+		}
+	}
+}
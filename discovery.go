@@ -0,0 +1,177 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"project/sstable"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// quarantineDir je direktorijum (unutar direktorijuma SSTable-ova) u koji se sklanjaju nepotpune generacije
+const quarantineDir = "QUARANTINE"
+
+// parseTableID izvlaci ID generacije iz imena fajla oblika usertable-00001-Data.db
+func parseTableID(name string) (int, bool) {
+	if !strings.HasPrefix(name, "usertable-") || !strings.HasSuffix(name, ".db") {
+		return 0, false
+	}
+	parts := strings.Split(strings.TrimPrefix(name, "usertable-"), "-")
+	if len(parts) != 2 {
+		return 0, false
+	}
+	id, err := strconv.Atoi(parts[0])
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+	return id, true
+}
+
+// isTablePart proverava da li je fajl privremeni deo tabele u jednom fajlu (npr. usertable-00001-Table.db.index.tmp).
+// Delovi se spajaju u tabelu pre nego sto je manifest navede, pa na disku ostaju samo ako je upis prekinut.
+func isTablePart(name string) bool {
+	end := strings.Index(name, ".db.")
+	if end == -1 || !strings.HasSuffix(name, ".tmp") {
+		return false
+	}
+	_, ok := parseTableID(name[:end+len(".db")])
+	return ok
+}
+
+// discoverTables skenira sstable direktorijume, ponovo otvara svaku kompletnu generaciju iz manifesta,
+// nepotpune (npr. od pada usred flush-a) premesta u karantin, brise privremene delove tabela u jednom fajlu
+// i nastavlja brojac posle najveceg ID-ja.
+func (m *FileManager) discoverTables() (*TableRegistry, error) {
+	// za svaki ID pamti koje delove (direktorijume) ima na disku
+	found := make(map[int]map[string]bool)
+	maxID := 0
+	for _, base := range append(tableDirs, singleTableDir) {
+		entries, err := os.ReadDir(filepath.Join(m.dir, base))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read sstable directory %s: %v", base, err)
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			if isTablePart(entry.Name()) {
+				fmt.Printf("Removing %s left by an interrupted SSTable write\n", entry.Name())
+				if err := os.Remove(filepath.Join(m.dir, base, entry.Name())); err != nil {
+					return nil, fmt.Errorf("failed to remove leftover SSTable part: %v", err)
+				}
+				continue
+			}
+			id, ok := parseTableID(entry.Name())
+			if !ok {
+				continue
+			}
+			if found[id] == nil {
+				found[id] = make(map[string]bool)
+			}
+			found[id][base] = true
+			if id > maxID {
+				maxID = id
+			}
+		}
+	}
+
+	// ID-jevi iz karantina se takodje preskacu da se fajlovi ne bi pomesali
+	quarantined, err := os.ReadDir(filepath.Join(m.dir, quarantineDir))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read quarantine directory: %v", err)
+	}
+	for _, entry := range quarantined {
+		if id, ok := parseTableID(entry.Name()); ok && id > maxID {
+			maxID = id
+		}
+	}
+
+	// Ako postoji manifest, on odredjuje koje su tabele zive, njihov nivo i redosled.
+	// Tabele kojih nema u manifestu su ostaci prekinutog flush-a ili kompakcije.
+	entries, hasManifest, err := loadManifest(m.manifestPath())
+	if err != nil {
+		return nil, err
+	}
+	if !hasManifest {
+		// stari format bez manifesta: sve tabele su na nivou 0, redom po ID-ju
+		ids := make([]int, 0, len(found))
+		for id := range found {
+			ids = append(ids, id)
+		}
+		sort.Ints(ids)
+		for _, id := range ids {
+			entries = append(entries, manifestEntry{id: id, level: 0})
+		}
+	}
+	live := make(map[int]bool)
+	for _, entry := range entries {
+		live[entry.id] = true
+	}
+
+	registry := NewTableRegistry(m.manifestPath())
+	for id := range found {
+		if !live[id] {
+			fmt.Printf("SSTable %d is not in manifest, moving it to quarantine\n", id)
+			if err := m.quarantine(m.tableFiles(id)); err != nil {
+				return nil, err
+			}
+			if err := m.quarantine(m.singleTableFiles(id)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, entry := range entries {
+		files, complete := m.foundTableFiles(entry.id, found[entry.id])
+		if !complete {
+			fmt.Printf("SSTable %d is incomplete, moving it to quarantine\n", entry.id)
+			if err := m.quarantine(files); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		table, err := sstable.OpenSSTable(entry.id, files, conf.BlockSize*5)
+		if err != nil {
+			fmt.Printf("SSTable %d cannot be opened (%v), moving it to quarantine\n", entry.id, err)
+			if err := m.quarantine(files); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		registry.addToLevel(table, entry.level)
+	}
+	for level := 1; level < registry.NumLevels(); level++ {
+		registry.sortLevel(level)
+	}
+	if err := registry.saveManifest(); err != nil {
+		return nil, err
+	}
+
+	m.sstableID = maxID + 1
+	fmt.Printf("Loaded %d SSTables from disk\n", registry.Len())
+	return registry, nil
+}
+
+// foundTableFiles vraca fajlove generacije u rasporedu u kom je nadjena na disku (nezavisno od configa)
+// i da li su na disku svi njeni delovi
+func (m *FileManager) foundTableFiles(id int, parts map[string]bool) (sstable.TableFiles, bool) {
+	if parts[singleTableDir] {
+		return m.singleTableFiles(id), true
+	}
+	return m.tableFiles(id), len(parts) == len(tableDirs)
+}
+
+// quarantine premesta sve postojece fajlove jedne generacije u QUARANTINE
+func (m *FileManager) quarantine(files sstable.TableFiles) error {
+	for _, path := range files.Paths() {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			continue
+		}
+		target := filepath.Join(m.dir, quarantineDir, filepath.Base(path))
+		if err := os.Rename(path, target); err != nil {
+			return fmt.Errorf("failed to quarantine %s: %v", path, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"project/blockmanager"
+	"project/sstable"
+	"testing"
+)
+
+func TestDiscoveryRemovesLeftoverTableParts(t *testing.T) {
+	mf := NewFileManager(t.TempDir())
+	if err := mf.ensureDirs(); err != nil {
+		t.Fatal(err)
+	}
+	record := blockmanager.SetRec(0, 1, 0, 1, 1, "k", []byte("v"))
+	table, err := sstable.CreateSSTable(1, mf.singleTableFiles(1), []*blockmanager.Record{record}, 4096, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry := NewTableRegistry(mf.manifestPath())
+	if err := registry.Add(table); err != nil {
+		t.Fatal(err)
+	}
+
+	// delovi tabele 2 ciji je upis prekinut pre spajanja, i fajl koji nije deo tabele
+	single := mf.singleTableFiles(2).Single
+	leftovers := []string{single + ".index.tmp", single + ".summary.tmp", single + ".filter.tmp", single + ".metadata.tmp"}
+	other := filepath.Join(mf.dir, singleTableDir, "notes.tmp")
+	for _, path := range append(leftovers, other) {
+		if err := os.WriteFile(path, []byte("partial"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	discovered, err := mf.discoverTables()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if discovered.Len() != 1 {
+		t.Fatalf("discovered %d tables, want 1", discovered.Len())
+	}
+	for _, path := range leftovers {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Fatalf("%s was not removed: %v", filepath.Base(path), err)
+		}
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Fatalf("discovery removed a file that is not a table part: %v", err)
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"project/memtable"
+	"sync"
+	"time"
+)
+
+// flushRetryInterval je pauza pre ponovnog pokusaja flush-a koji nije uspeo
+const flushRetryInterval = time.Second
+
+// FlushStatus opisuje stanje pozadinskog flush-a
+type FlushStatus struct {
+	Pending   int   // zapecacene tabele koje jos nisu upisane u SSTable
+	Flushed   int   // tabele upisane od pokretanja
+	LastError error // greska poslednjeg flush-a ili kompakcije, nil ako je uspeo
+}
+
+// flusher u posebnoj gorutini upisuje zapecacene tabele memtable-a u SSTable-ove, redom kojim su zapecacene.
+// Kanal prima sve tabele memtable-a zapecacene odjednom; kada flush prethodnih kasni,
+// slanje u pun kanal blokira upis umesto da se podaci odbace.
+type flusher struct {
+	queue   chan *memtable.ImmutableMemTable
+	done    sync.WaitGroup
+	lock    sync.Mutex // cuva status
+	status  FlushStatus
+	closing bool
+}
+
+func newFlusher(capacity int) *flusher {
+	return &flusher{
+		queue: make(chan *memtable.ImmutableMemTable, capacity),
+	}
+}
+
+// start pokrece gorutinu koja flush-uje tabele dok se kanal ne zatvori
+func (f *flusher) start(manager *Manager) {
+	f.done.Add(1)
+	go func() {
+		defer f.done.Done()
+		for imt := range f.queue {
+			// neuspeo flush se ponavlja, tabela za to vreme ostaje citljiva medju immutable memtable-ovima
+			for {
+				err := manager.flushImmutable(imt)
+				f.setResult(err)
+				if err == nil {
+					break
+				}
+				fmt.Printf("Background flush failed: %v\n", err)
+				time.Sleep(flushRetryInterval)
+			}
+			if err := manager.compact(); err != nil {
+				f.setResult(err)
+				fmt.Printf("Background compaction failed: %v\n", err)
+			}
+		}
+	}()
+}
+
+// submit predaje zapecacenu tabelu flusher-u; blokira dok u kanalu nema mesta
+func (f *flusher) submit(imt *memtable.ImmutableMemTable) {
+	f.lock.Lock()
+	f.status.Pending++
+	f.lock.Unlock()
+	f.queue <- imt
+}
+
+// setResult belezi ishod jednog flush-a ili kompakcije
+func (f *flusher) setResult(err error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.status.LastError = err
+}
+
+// flushed belezi da je tabela upisana i objavljena za citanje
+func (f *flusher) flushed() {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.status.Pending--
+	f.status.Flushed++
+}
+
+func (f *flusher) getStatus() FlushStatus {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.status
+}
+
+// stop zatvara kanal i ceka da se upisu sve tabele koje su vec predate
+func (f *flusher) stop() {
+	f.lock.Lock()
+	if f.closing {
+		f.lock.Unlock()
+		return
+	}
+	f.closing = true
+	f.lock.Unlock()
+
+	close(f.queue)
+	f.done.Wait()
+}
@@ -0,0 +1,32 @@
+package hashfunc
+
+import (
+	"crypto/md5"
+	"encoding/binary"
+	"time"
+)
+
+// HashWithSeed je hash funkcija sa seed vrednoscu; koriste je Bloom filter i Count-Min Sketch
+type HashWithSeed struct {
+	Seed []byte
+}
+
+// Hash vraca prvih 8 bajtova md5 sume podataka i seed-a
+func (h HashWithSeed) Hash(data []byte) uint64 {
+	fn := md5.New()
+	fn.Write(data)
+	fn.Write(h.Seed)
+	return binary.BigEndian.Uint64(fn.Sum(nil))
+}
+
+// CreateHashFunctions kreira k razlicitih hash funkcija sa razlicitim seed-ovima
+func CreateHashFunctions(k uint32) []HashWithSeed {
+	h := make([]HashWithSeed, k)
+	ts := uint32(time.Now().Unix())
+	for i := uint32(0); i < k; i++ {
+		seed := make([]byte, 4)
+		binary.BigEndian.PutUint32(seed, ts+i)
+		h[i] = HashWithSeed{Seed: seed}
+	}
+	return h
+}
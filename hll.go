@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"project/hyperloglog"
+)
+
+// HLLCreate pravi prazan HLL date preciznosti pod kljucem key
+func (manager *Manager) HLLCreate(key string, precision uint8) error {
+	if err := manager.takeToken(); err != nil {
+		return err
+	}
+	hll, err := hyperloglog.NewHLL(precision)
+	if err != nil {
+		return err
+	}
+	return manager.updateValue(key, func(value []byte) ([]byte, error) {
+		if value != nil {
+			return nil, fmt.Errorf("key %q already exists", key)
+		}
+		return encodeTyped(ValueHLL, hll.Serialize()), nil
+	})
+}
+
+// HLLAdd dodaje element u HLL sacuvan pod kljucem key
+func (manager *Manager) HLLAdd(key string, item []byte) error {
+	if err := manager.takeToken(); err != nil {
+		return err
+	}
+	return manager.updateValue(key, func(value []byte) ([]byte, error) {
+		hll, err := decodeHLL(key, value)
+		if err != nil {
+			return nil, err
+		}
+		hll.Add(item)
+		return encodeTyped(ValueHLL, hll.Serialize()), nil
+	})
+}
+
+// HLLEstimate vraca procenu broja razlicitih elemenata u HLL-u pod kljucem key
+func (manager *Manager) HLLEstimate(key string) (float64, error) {
+	if err := manager.takeToken(); err != nil {
+		return 0, err
+	}
+	value, err := manager.getValue(key)
+	if err != nil {
+		return 0, err
+	}
+	hll, err := decodeHLL(key, value)
+	if err != nil {
+		return 0, err
+	}
+	return hll.Estimate(), nil
+}
+
+// HLLDelete brise HLL sacuvan pod kljucem key
+func (manager *Manager) HLLDelete(key string) error {
+	if err := manager.takeToken(); err != nil {
+		return err
+	}
+	return manager.updateValue(key, func(value []byte) ([]byte, error) {
+		if _, err := decodeTyped(ValueHLL, key, value); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	})
+}
+
+// decodeHLL deserijalizuje sacuvanu vrednost; nil znaci da HLL ne postoji
+func decodeHLL(key string, value []byte) (*hyperloglog.HLL, error) {
+	data, err := decodeTyped(ValueHLL, key, value)
+	if err != nil {
+		return nil, err
+	}
+	hll, err := hyperloglog.Deserialize(data)
+	if err != nil {
+		return nil, fmt.Errorf("hll %q is corrupted: %v", key, err)
+	}
+	return hll, nil
+}
@@ -0,0 +1,133 @@
+package hyperloglog
+
+import (
+	"crypto/md5"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/bits"
+)
+
+// Dozvoljene preciznosti: HLL ima 2^p registara
+const (
+	HLL_MIN_PRECISION = 4
+	HLL_MAX_PRECISION = 16
+)
+
+// HLL procenjuje broj razlicitih elemenata. Prvih p bita hash-a bira registar,
+// a registar pamti najveci broj vodecih nula (+1) u ostatku hash-a.
+type HLL struct {
+	m   uint64 // broj registara
+	p   uint8  // preciznost
+	reg []uint8
+}
+
+// NewHLL pravi prazan HLL sa 2^p registara
+func NewHLL(p uint8) (*HLL, error) {
+	if p < HLL_MIN_PRECISION || p > HLL_MAX_PRECISION {
+		return nil, fmt.Errorf("hll precision must be between %d and %d, got %d", HLL_MIN_PRECISION, HLL_MAX_PRECISION, p)
+	}
+	m := uint64(1) << p
+	return &HLL{
+		m:   m,
+		p:   p,
+		reg: make([]uint8, m),
+	}, nil
+}
+
+// Getteri
+func (hll *HLL) GetM() uint64        { return hll.m }
+func (hll *HLL) GetPrecision() uint8 { return hll.p }
+
+// hash uzima prvih 8 bajtova md5 sume; vodeci biti moraju biti dobro rasporedjeni jer biraju registar
+func hash(data []byte) uint64 {
+	sum := md5.Sum(data)
+	return binary.BigEndian.Uint64(sum[:8])
+}
+
+// Add dodaje element
+func (hll *HLL) Add(data []byte) {
+	h := hash(data)
+	bucket := h >> (64 - hll.p)
+	// vodece nule u preostalih 64-p bita; ako su svi nula, rang je 64-p+1
+	rest := h << hll.p
+	rank := uint8(bits.LeadingZeros64(rest)) + 1
+	if maxRank := 64 - hll.p + 1; rank > maxRank {
+		rank = maxRank
+	}
+	if rank > hll.reg[bucket] {
+		hll.reg[bucket] = rank
+	}
+}
+
+// Estimate vraca procenu broja razlicitih elemenata
+func (hll *HLL) Estimate() float64 {
+	sum := 0.0
+	for _, val := range hll.reg {
+		sum += math.Pow(2.0, -float64(val))
+	}
+
+	alpha := 0.7213 / (1.0 + 1.079/float64(hll.m))
+	estimation := alpha * float64(hll.m) * float64(hll.m) / sum
+	emptyRegs := hll.emptyCount()
+	if estimation <= 2.5*float64(hll.m) { // korekcija za male vrednosti
+		if emptyRegs > 0 {
+			estimation = float64(hll.m) * math.Log(float64(hll.m)/float64(emptyRegs))
+		}
+	} else if estimation > 1/30.0*math.Pow(2.0, 32.0) { // korekcija za velike vrednosti
+		estimation = -math.Pow(2.0, 32.0) * math.Log(1.0-estimation/math.Pow(2.0, 32.0))
+	}
+	return estimation
+}
+
+func (hll *HLL) emptyCount() int {
+	sum := 0
+	for _, val := range hll.reg {
+		if val == 0 {
+			sum++
+		}
+	}
+	return sum
+}
+
+// Merge dodaje sve elemente drugog HLL-a (iste preciznosti) u ovaj
+func (hll *HLL) Merge(other *HLL) error {
+	if other.p != hll.p {
+		return fmt.Errorf("cannot merge hll with precision %d into hll with precision %d", other.p, hll.p)
+	}
+	for i, val := range other.reg {
+		if val > hll.reg[i] {
+			hll.reg[i] = val
+		}
+	}
+	return nil
+}
+
+// Serialize vraca HLL kao niz bajtova: preciznost (1 bajt) pa registri, po jedan bajt
+func (hll *HLL) Serialize() []byte {
+	data := make([]byte, 0, 1+len(hll.reg))
+	data = append(data, hll.p)
+	return append(data, hll.reg...)
+}
+
+// Deserialize cita HLL zapisan sa Serialize
+func Deserialize(data []byte) (*HLL, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("hll data is empty")
+	}
+	hll, err := NewHLL(data[0])
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(data)-1) != hll.m {
+		return nil, fmt.Errorf("hll data has %d registers, expected %d", len(data)-1, hll.m)
+	}
+	// registar nikad nije veci od najveceg ranga koji Add moze da upise
+	for i, val := range data[1:] {
+		if val > 64-hll.p+1 {
+			return nil, fmt.Errorf("hll register %d has rank %d, at most %d is possible", i, val, 64-hll.p+1)
+		}
+	}
+	copy(hll.reg, data[1:])
+	return hll, nil
+}
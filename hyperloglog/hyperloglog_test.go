@@ -0,0 +1,83 @@
+package hyperloglog
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func addItems(hll *HLL, prefix string, n int) {
+	for i := 0; i < n; i++ {
+		hll.Add([]byte(fmt.Sprintf("%s-%d", prefix, i)))
+	}
+}
+
+func TestEstimate(t *testing.T) {
+	hll, err := NewHLL(12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addItems(hll, "item", 10000)
+	// ponovljeni elementi ne menjaju procenu
+	addItems(hll, "item", 10000)
+	// standardna greska za p=12 je oko 1.6%, pa je 5% sigurna granica
+	if estimate := hll.Estimate(); math.Abs(estimate-10000) > 500 {
+		t.Fatalf("Estimate = %.0f, want about 10000", estimate)
+	}
+	if _, err := NewHLL(HLL_MIN_PRECISION - 1); err == nil {
+		t.Fatal("NewHLL accepted a precision below the minimum")
+	}
+	if _, err := NewHLL(HLL_MAX_PRECISION + 1); err == nil {
+		t.Fatal("NewHLL accepted a precision above the maximum")
+	}
+}
+
+func TestMerge(t *testing.T) {
+	a, _ := NewHLL(10)
+	b, _ := NewHLL(10)
+	addItems(a, "a", 1000)
+	addItems(b, "b", 1000)
+	if err := a.Merge(b); err != nil {
+		t.Fatal(err)
+	}
+	if estimate := a.Estimate(); math.Abs(estimate-2000) > 200 {
+		t.Fatalf("Estimate after merge = %.0f, want about 2000", estimate)
+	}
+
+	other, _ := NewHLL(11)
+	if err := a.Merge(other); err == nil {
+		t.Fatal("Merge accepted an hll with a different precision")
+	}
+}
+
+func TestSerializeRoundTrip(t *testing.T) {
+	hll, _ := NewHLL(8)
+	addItems(hll, "item", 300)
+	restored, err := Deserialize(hll.Serialize())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.GetPrecision() != hll.GetPrecision() || restored.Estimate() != hll.Estimate() {
+		t.Fatalf("restored hll has precision %d and estimate %.0f, want %d and %.0f",
+			restored.GetPrecision(), restored.Estimate(), hll.GetPrecision(), hll.Estimate())
+	}
+}
+
+func TestDeserializeCorrupted(t *testing.T) {
+	hll, _ := NewHLL(4)
+	data := hll.Serialize()
+	badRank := append([]byte(nil), data...)
+	badRank[1] = 64 - 4 + 2
+	cases := map[string][]byte{
+		"empty":           nil,
+		"precision":       append([]byte{HLL_MAX_PRECISION + 1}, data[1:]...),
+		"missing":         data[:len(data)-1],
+		"extra":           append(append([]byte(nil), data...), 0),
+		"impossible rank": badRank,
+	}
+	for name, corrupted := range cases {
+		if _, err := Deserialize(corrupted); err == nil {
+			t.Errorf("%s: Deserialize succeeded", name)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"fmt"
+	"project/blockmanager"
+	"project/memtable"
+	"project/sstable"
+	"strings"
+)
+
+// scanSource je jedan sortiran izvor rekorda za ScanIterator (memtable ili SSTable)
+type scanSource interface {
+	next() (*blockmanager.Record, bool, error)
+	close() error
+}
+
+// memtableSource vraca rekorde iz opsega kopirane iz memtable-a ili zapecacene tabele.
+// Memtable se menja cim iterator pusti lock, pa se rekordi kopiraju odmah pri kreiranju iteratora.
+type memtableSource struct {
+	records []*blockmanager.Record
+}
+
+func newMemtableSource(it memtable.MemTableIterator, minKey string, inRange func(key string) bool) *memtableSource {
+	source := &memtableSource{}
+	for it.Seek(minKey); it.Valid() && inRange(it.Record().GetKey()); it.Next() {
+		source.records = append(source.records, it.Record())
+	}
+	return source
+}
+
+func (s *memtableSource) next() (*blockmanager.Record, bool, error) {
+	if len(s.records) == 0 {
+		return nil, false, nil
+	}
+	record := s.records[0]
+	s.records = s.records[1:]
+	return record, true, nil
+}
+
+func (s *memtableSource) close() error {
+	s.records = nil
+	return nil
+}
+
+// tableSource cita jednu SSTable tabelu preko otvorenog data fajla
+type tableSource struct {
+	it *sstable.Iterator
+}
+
+func (s *tableSource) next() (*blockmanager.Record, bool, error) {
+	return s.it.Next()
+}
+
+func (s *tableSource) close() error {
+	return s.it.Close()
+}
+
+// ScanIterator redom po kljucu vraca zive rekorde iz opsega, spajajuci memtable i sve SSTable-ove.
+// Za svaki kljuc vazi verzija iz najnovijeg izvora, istim redosledom kao kod GET-a,
+// a obrisani i sistemski kljucevi se preskacu. Data fajlovi tabela ostaju otvoreni dok se ne pozove Stop.
+type ScanIterator struct {
+	sources []scanSource // od najnovijeg ka najstarijem
+	heads   []*blockmanager.Record
+	err     error
+	stopped bool
+}
+
+// RangeIterate vraca iterator nad zivim rekordima sa kljucem u [minKey, maxKey]
+func (manager *Manager) RangeIterate(minKey, maxKey string) (*ScanIterator, error) {
+	if minKey > maxKey {
+		return nil, fmt.Errorf("min key %q is greater than max key %q", minKey, maxKey)
+	}
+	return manager.newScanIterator(minKey, func(key string) bool {
+		return key <= maxKey
+	})
+}
+
+// PrefixIterate vraca iterator nad zivim rekordima ciji kljuc pocinje sa prefix
+func (manager *Manager) PrefixIterate(prefix string) (*ScanIterator, error) {
+	// svi kljucevi sa prefiksom su >= prefix i cine jedan neprekidan niz u sortiranom redosledu
+	return manager.newScanIterator(prefix, func(key string) bool {
+		return strings.HasPrefix(key, prefix)
+	})
+}
+
+// newScanIterator otvara izvore od najnovijeg ka najstarijem: prvo memtable i zapecacene tabele,
+// pa SSTable-ove redom kojim ih cita i GET, i ucitava prvi rekord iz svakog
+func (manager *Manager) newScanIterator(minKey string, inRange func(key string) bool) (*ScanIterator, error) {
+	iterator := &ScanIterator{}
+	// spisak izvora se uzima odjednom da flush ili kompakcija ne bi izmenili registar usred otvaranja
+	manager.lock.RLock()
+	defer manager.lock.RUnlock()
+
+	iterator.sources = append(iterator.sources, newMemtableSource(manager.memtable.Iterator(), minKey, inRange))
+	for i := len(manager.immutables) - 1; i >= 0; i-- {
+		iterator.sources = append(iterator.sources, newMemtableSource(manager.immutables[i].Iterator(), minKey, inRange))
+	}
+
+	for _, table := range manager.tables.NewestFirst() {
+		// tabele ciji opseg (iz summary hedera) ne sece upit se ni ne otvaraju
+		if !table.MayContainRange(minKey, inRange) {
+			continue
+		}
+		it, err := table.NewIterator(minKey, inRange)
+		if err != nil {
+			iterator.Stop()
+			return nil, fmt.Errorf("failed to scan sstable %d: %v", table.GetID(), err)
+		}
+		iterator.sources = append(iterator.sources, &tableSource{it: it})
+	}
+
+	iterator.heads = make([]*blockmanager.Record, len(iterator.sources))
+	for i := range iterator.sources {
+		if err := iterator.advance(i); err != nil {
+			iterator.Stop()
+			return nil, err
+		}
+	}
+	return iterator, nil
+}
+
+// advance ucitava sledeci rekord izvora i u heads; nil ako je izvor potrosen
+func (iterator *ScanIterator) advance(i int) error {
+	record, ok, err := iterator.sources[i].next()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		record = nil
+	}
+	iterator.heads[i] = record
+	return nil
+}
+
+// Next vraca sledeci zivi rekord; false kada ih vise nema, posle Stop ili ako citanje nije uspelo (vidi Err)
+func (iterator *ScanIterator) Next() (*blockmanager.Record, bool) {
+	for !iterator.stopped {
+		// najmanji kljuc medju trenutnim rekordima
+		key, found := "", false
+		for _, head := range iterator.heads {
+			if head != nil && (!found || head.GetKey() < key) {
+				key, found = head.GetKey(), true
+			}
+		}
+		if !found {
+			return nil, false
+		}
+
+		// najnovija verzija kljuca je u prvom (najnovijem) izvoru koji ga ima, ostali se samo pomeraju
+		var newest *blockmanager.Record
+		for i, head := range iterator.heads {
+			if head == nil || head.GetKey() != key {
+				continue
+			}
+			if newest == nil {
+				newest = head
+			}
+			if err := iterator.advance(i); err != nil {
+				iterator.err = err
+				iterator.Stop()
+				return nil, false
+			}
+		}
+		// sistemski kljucevi nisu deo korisnickih podataka
+		if newest.GetTombstone() == 0 && !isSystemKey(newest.GetKey()) {
+			return newest, true
+		}
+	}
+	return nil, false
+}
+
+// Err vraca gresku zbog koje je iterator prekinut, ako je bilo
+func (iterator *ScanIterator) Err() error {
+	return iterator.err
+}
+
+// Stop zatvara sve otvorene fajlove; posle Stop iterator ne vraca vise rekorde
+func (iterator *ScanIterator) Stop() {
+	if iterator.stopped {
+		return
+	}
+	iterator.stopped = true
+	for _, source := range iterator.sources {
+		if err := source.close(); err != nil && iterator.err == nil {
+			iterator.err = err
+		}
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"bufio"
 	"fmt"
 	"os"
+	"project/blockmanager"
 	"project/memtable"
+	"strconv"
 
 	//"project/sstable"
 	"strings"
@@ -15,8 +17,13 @@ var manager *Manager
 func main() {
 	fmt.Println("=== NASP PROJEKAT - LSM TREE SISTEM ===")
 
-	// Izbor tipa memtable-a
-	memTableType := chooseMemTableType()
+	// Tip memtable-a iz configa (vec proveren u LoadConfig), a ako nije zadat bira se ovde
+	var memTableType memtable.MemTableType
+	if conf.MemtableType == "" {
+		memTableType = chooseMemTableType()
+	} else {
+		memTableType, _ = memtable.ParseMemTableType(conf.MemtableType)
+	}
 
 	// Kreiranje Manager-a
 	fmt.Printf("\nKreiranje sistema sa %s memtable...\n", memTableType.String())
@@ -42,8 +49,24 @@ func main() {
 			handleDELETE(scanner)
 		case "4":
 			showMemTableContent()
+		case "5":
+			handleRangeScan(scanner)
+		case "6":
+			handlePrefixScan(scanner)
+		case "7":
+			handleHLL(scanner)
+		case "8":
+			handleCMS(scanner)
+		case "9":
+			handleSimHash(scanner)
+		case "10":
+			handleBloom(scanner)
 		case "0":
 			fmt.Println("Izlazim iz programa...")
+			// sacekaj da se zapecacene tabele upisu na disk
+			if err := manager.Close(); err != nil {
+				fmt.Printf("Greška pri flush-u: %v\n", err)
+			}
 			return
 		default:
 			fmt.Println("Nevaljan izbor! Pokušajte ponovo.")
@@ -86,6 +109,12 @@ func showMainMenu() {
 	fmt.Println("2. GET - Pronađi podatak")
 	fmt.Println("3. DELETE - Obriši podatak")
 	fmt.Println("4. FLUSH - Prikaži sadržaj memtable")
+	fmt.Println("5. RANGE_SCAN - Pretraga opsega ključeva")
+	fmt.Println("6. PREFIX_SCAN - Pretraga po prefiksu ključa")
+	fmt.Println("7. HLL - HyperLogLog operacije")
+	fmt.Println("8. CMS - Count-Min Sketch operacije")
+	fmt.Println("9. SIMHASH - SimHash otisci teksta")
+	fmt.Println("10. BLOOM - Bloom filter operacije")
 	fmt.Println("0. IZLAZ")
 	fmt.Println("-------------------")
 }
@@ -118,8 +147,10 @@ func handleGET(scanner *bufio.Scanner) {
 	}
 	key := strings.TrimSpace(scanner.Text())
 
-	result := manager.GET(key)
-	if result == nil {
+	result, err := manager.GET(key)
+	if err != nil {
+		fmt.Printf("GREŠKA: %v\n", err)
+	} else if result == nil {
 		fmt.Printf("Ključ '%s' nije pronađen\n", key)
 	} else {
 		fmt.Printf("GET uspešan: %s = %s\n", key, string(result))
@@ -141,6 +172,337 @@ func handleDELETE(scanner *bufio.Scanner) {
 	}
 }
 
+func handleRangeScan(scanner *bufio.Scanner) {
+	fmt.Print("Unesite početni ključ: ")
+	if !scanner.Scan() {
+		return
+	}
+	minKey := strings.TrimSpace(scanner.Text())
+
+	fmt.Print("Unesite krajnji ključ: ")
+	if !scanner.Scan() {
+		return
+	}
+	maxKey := strings.TrimSpace(scanner.Text())
+
+	pageNumber, pageSize, ok := readPage(scanner)
+	if !ok {
+		return
+	}
+
+	records, err := manager.RangeScan(minKey, maxKey, pageNumber, pageSize)
+	if err != nil {
+		fmt.Printf("GREŠKA: %v\n", err)
+		return
+	}
+	printPage(records, pageNumber)
+}
+
+func handlePrefixScan(scanner *bufio.Scanner) {
+	fmt.Print("Unesite prefiks: ")
+	if !scanner.Scan() {
+		return
+	}
+	prefix := strings.TrimSpace(scanner.Text())
+
+	pageNumber, pageSize, ok := readPage(scanner)
+	if !ok {
+		return
+	}
+
+	records, err := manager.PrefixScan(prefix, pageNumber, pageSize)
+	if err != nil {
+		fmt.Printf("GREŠKA: %v\n", err)
+		return
+	}
+	printPage(records, pageNumber)
+}
+
+// readPage učitava broj stranice i veličinu stranice
+func readPage(scanner *bufio.Scanner) (int, int, bool) {
+	fmt.Print("Unesite broj stranice: ")
+	if !scanner.Scan() {
+		return 0, 0, false
+	}
+	pageNumber, err := strconv.Atoi(strings.TrimSpace(scanner.Text()))
+	if err != nil {
+		fmt.Println("Broj stranice mora biti ceo broj!")
+		return 0, 0, false
+	}
+
+	fmt.Print("Unesite veličinu stranice: ")
+	if !scanner.Scan() {
+		return 0, 0, false
+	}
+	pageSize, err := strconv.Atoi(strings.TrimSpace(scanner.Text()))
+	if err != nil {
+		fmt.Println("Veličina stranice mora biti ceo broj!")
+		return 0, 0, false
+	}
+	return pageNumber, pageSize, true
+}
+
+func printPage(records []*blockmanager.Record, pageNumber int) {
+	if len(records) == 0 {
+		fmt.Printf("Stranica %d je prazna\n", pageNumber)
+		return
+	}
+	fmt.Printf("=== STRANICA %d ===\n", pageNumber)
+	for _, record := range records {
+		fmt.Printf("%s = %s\n", record.GetKey(), string(record.GetValue()))
+	}
+}
+
+// readLine ispisuje poruku i ucitava jedan red; false ako je ulaz zatvoren
+func readLine(scanner *bufio.Scanner, prompt string) (string, bool) {
+	fmt.Print(prompt)
+	if !scanner.Scan() {
+		return "", false
+	}
+	return strings.TrimSpace(scanner.Text()), true
+}
+
+func handleHLL(scanner *bufio.Scanner) {
+	fmt.Println("1. Kreiraj HLL")
+	fmt.Println("2. Dodaj element")
+	fmt.Println("3. Procena broja različitih elemenata")
+	fmt.Println("4. Obriši HLL")
+	choice, ok := readLine(scanner, "Izbor: ")
+	if !ok {
+		return
+	}
+	key, ok := readLine(scanner, "Unesite ključ HLL-a: ")
+	if !ok {
+		return
+	}
+
+	var err error
+	switch choice {
+	case "1":
+		input, ok := readLine(scanner, "Unesite preciznost (4-16): ")
+		if !ok {
+			return
+		}
+		precision, convErr := strconv.ParseUint(input, 10, 8)
+		if convErr != nil {
+			fmt.Println("Preciznost mora biti ceo broj!")
+			return
+		}
+		if err = manager.HLLCreate(key, uint8(precision)); err == nil {
+			fmt.Printf("HLL '%s' je kreiran\n", key)
+		}
+	case "2":
+		item, ok := readLine(scanner, "Unesite element: ")
+		if !ok {
+			return
+		}
+		if err = manager.HLLAdd(key, []byte(item)); err == nil {
+			fmt.Printf("Element '%s' je dodat u HLL '%s'\n", item, key)
+		}
+	case "3":
+		var estimate float64
+		if estimate, err = manager.HLLEstimate(key); err == nil {
+			fmt.Printf("Procena za HLL '%s': %.0f\n", key, estimate)
+		}
+	case "4":
+		if err = manager.HLLDelete(key); err == nil {
+			fmt.Printf("HLL '%s' je obrisan\n", key)
+		}
+	default:
+		fmt.Println("Nevaljan izbor!")
+	}
+	if err != nil {
+		fmt.Printf("GREŠKA: %v\n", err)
+	}
+}
+
+func handleCMS(scanner *bufio.Scanner) {
+	fmt.Println("1. Kreiraj CMS")
+	fmt.Println("2. Dodaj element")
+	fmt.Println("3. Procena učestalosti elementa")
+	fmt.Println("4. Obriši CMS")
+	choice, ok := readLine(scanner, "Izbor: ")
+	if !ok {
+		return
+	}
+	key, ok := readLine(scanner, "Unesite ključ CMS-a: ")
+	if !ok {
+		return
+	}
+
+	var err error
+	switch choice {
+	case "1":
+		epsilon, ok := readFloat(scanner, "Unesite dozvoljenu grešku epsilon (npr. 0.01): ")
+		if !ok {
+			return
+		}
+		delta, ok := readFloat(scanner, "Unesite verovatnoću greške delta (npr. 0.01): ")
+		if !ok {
+			return
+		}
+		if err = manager.CMSCreate(key, epsilon, delta); err == nil {
+			fmt.Printf("CMS '%s' je kreiran\n", key)
+		}
+	case "2":
+		item, ok := readLine(scanner, "Unesite element: ")
+		if !ok {
+			return
+		}
+		if err = manager.CMSAdd(key, []byte(item), 1); err == nil {
+			fmt.Printf("Element '%s' je dodat u CMS '%s'\n", item, key)
+		}
+	case "3":
+		item, ok := readLine(scanner, "Unesite element: ")
+		if !ok {
+			return
+		}
+		var count uint64
+		if count, err = manager.CMSQuery(key, []byte(item)); err == nil {
+			fmt.Printf("Procena učestalosti elementa '%s' u CMS-u '%s': %d\n", item, key, count)
+		}
+	case "4":
+		if err = manager.CMSDelete(key); err == nil {
+			fmt.Printf("CMS '%s' je obrisan\n", key)
+		}
+	default:
+		fmt.Println("Nevaljan izbor!")
+	}
+	if err != nil {
+		fmt.Printf("GREŠKA: %v\n", err)
+	}
+}
+
+func handleSimHash(scanner *bufio.Scanner) {
+	fmt.Println("1. Sačuvaj otisak teksta")
+	fmt.Println("2. Prikaži otisak")
+	fmt.Println("3. Hamming rastojanje dva otiska")
+	fmt.Println("4. Obriši otisak")
+	choice, ok := readLine(scanner, "Izbor: ")
+	if !ok {
+		return
+	}
+	key, ok := readLine(scanner, "Unesite ključ otiska: ")
+	if !ok {
+		return
+	}
+
+	var err error
+	switch choice {
+	case "1":
+		text, ok := readLine(scanner, "Unesite tekst: ")
+		if !ok {
+			return
+		}
+		if err = manager.SimHashPut(key, text); err == nil {
+			fmt.Printf("Otisak teksta je sačuvan pod ključem '%s'\n", key)
+		}
+	case "2":
+		var fingerprint uint64
+		if fingerprint, err = manager.SimHashGet(key); err == nil {
+			fmt.Printf("Otisak '%s': %016x\n", key, fingerprint)
+		}
+	case "3":
+		other, ok := readLine(scanner, "Unesite ključ drugog otiska: ")
+		if !ok {
+			return
+		}
+		var distance int
+		if distance, err = manager.SimHashDistance(key, other); err == nil {
+			fmt.Printf("Hamming rastojanje između '%s' i '%s': %d\n", key, other, distance)
+		}
+	case "4":
+		if err = manager.SimHashDelete(key); err == nil {
+			fmt.Printf("Otisak '%s' je obrisan\n", key)
+		}
+	default:
+		fmt.Println("Nevaljan izbor!")
+	}
+	if err != nil {
+		fmt.Printf("GREŠKA: %v\n", err)
+	}
+}
+
+func handleBloom(scanner *bufio.Scanner) {
+	fmt.Println("1. Kreiraj Bloom filter")
+	fmt.Println("2. Dodaj element")
+	fmt.Println("3. Proveri element")
+	fmt.Println("4. Obriši Bloom filter")
+	choice, ok := readLine(scanner, "Izbor: ")
+	if !ok {
+		return
+	}
+	key, ok := readLine(scanner, "Unesite ključ Bloom filtera: ")
+	if !ok {
+		return
+	}
+
+	var err error
+	switch choice {
+	case "1":
+		input, ok := readLine(scanner, "Unesite očekivani broj elemenata: ")
+		if !ok {
+			return
+		}
+		expected, convErr := strconv.Atoi(input)
+		if convErr != nil {
+			fmt.Println("Broj elemenata mora biti ceo broj!")
+			return
+		}
+		rate, ok := readFloat(scanner, "Unesite verovatnoću lažno pozitivnog (npr. 0.01): ")
+		if !ok {
+			return
+		}
+		if err = manager.BloomCreate(key, expected, rate); err == nil {
+			fmt.Printf("Bloom filter '%s' je kreiran\n", key)
+		}
+	case "2":
+		item, ok := readLine(scanner, "Unesite element: ")
+		if !ok {
+			return
+		}
+		if err = manager.BloomAdd(key, []byte(item)); err == nil {
+			fmt.Printf("Element '%s' je dodat u Bloom filter '%s'\n", item, key)
+		}
+	case "3":
+		item, ok := readLine(scanner, "Unesite element: ")
+		if !ok {
+			return
+		}
+		var found bool
+		if found, err = manager.BloomCheck(key, []byte(item)); err == nil {
+			if found {
+				fmt.Printf("Element '%s' možda postoji u Bloom filteru '%s'\n", item, key)
+			} else {
+				fmt.Printf("Element '%s' sigurno ne postoji u Bloom filteru '%s'\n", item, key)
+			}
+		}
+	case "4":
+		if err = manager.BloomDelete(key); err == nil {
+			fmt.Printf("Bloom filter '%s' je obrisan\n", key)
+		}
+	default:
+		fmt.Println("Nevaljan izbor!")
+	}
+	if err != nil {
+		fmt.Printf("GREŠKA: %v\n", err)
+	}
+}
+
+// readFloat ucitava realan broj; false ako je ulaz zatvoren ili neispravan
+func readFloat(scanner *bufio.Scanner, prompt string) (float64, bool) {
+	input, ok := readLine(scanner, prompt)
+	if !ok {
+		return 0, false
+	}
+	value, err := strconv.ParseFloat(input, 64)
+	if err != nil {
+		fmt.Println("Vrednost mora biti broj!")
+		return 0, false
+	}
+	return value, true
+}
+
 func showMemTableContent() {
 	fmt.Println("=== SADRŽAJ MEMTABLE ===")
 	size := manager.memtable.GetSize()
@@ -152,6 +514,11 @@ func showMemTableContent() {
 	} else {
 		fmt.Println("Status: Ima mesta")
 	}
+	status := manager.FlushStatus()
+	fmt.Printf("Tabele koje čekaju flush: %d (upisano: %d)\n", status.Pending, status.Flushed)
+	if status.LastError != nil {
+		fmt.Printf("Poslednja greška flush-a: %v\n", status.LastError)
+	}
 	fmt.Println()
 
 	manager.memtable.Dump()
@@ -9,38 +9,110 @@ package main
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"project/blockmanager"
 	"project/cache"
 	"project/memtable"
 	"project/sstable"
+	"project/tokenbucket"
 	wal "project/walFile"
+	"sync"
+	"time"
 )
 
+// tableDirs su direktorijumi (unutar direktorijuma SSTable-ova) u kojima se nalazi po jedan fajl svake generacije
+var tableDirs = []string{"DATA", "INDEX", "SUMMARY", "FILTER", "METADATA"}
+
+// singleTableDir je direktorijum (unutar direktorijuma SSTable-ova) sa generacijama zapisanim u jednom fajlu
+const singleTableDir = "TABLE"
+
 type FileManager struct {
+	dir       string // direktorijum SSTable-ova
 	sstableID int
 }
 
-func NewFileManager() *FileManager {
+func NewFileManager(dir string) *FileManager {
 	return &FileManager{
+		dir:       dir,
 		sstableID: 1,
 	}
 }
 
-func (m *FileManager) nextFileName(base, suffix string) string {
-	return fmt.Sprintf("sstable/%s/usertable-%05d-%s.db", base, m.sstableID, suffix)
+func (m *FileManager) fileName(id int, base, suffix string) string {
+	return filepath.Join(m.dir, base, fmt.Sprintf("usertable-%05d-%s.db", id, suffix))
+}
+
+// manifestPath vraca putanju manifesta zivih tabela
+func (m *FileManager) manifestPath() string {
+	return filepath.Join(m.dir, manifestFile)
+}
+
+// tableFiles vraca imena svih fajlova generacije SSTable-a sa datim ID-jem
+func (m *FileManager) tableFiles(id int) sstable.TableFiles {
+	return sstable.TableFiles{
+		Data:     m.fileName(id, "DATA", "Data"),
+		Index:    m.fileName(id, "INDEX", "Index"),
+		Summary:  m.fileName(id, "SUMMARY", "Summary"),
+		Filter:   m.fileName(id, "FILTER", "Filter"),
+		Metadata: m.fileName(id, "METADATA", "Metadata"),
+	}
+}
+
+// singleTableFiles vraca ime jednog fajla generacije SSTable-a sa datim ID-jem
+func (m *FileManager) singleTableFiles(id int) sstable.TableFiles {
+	return sstable.TableFiles{
+		Single: m.fileName(id, singleTableDir, "Table"),
+	}
+}
+
+// nextTableFiles vraca imena svih fajlova za sledecu generaciju SSTable-a, u rasporedu iz configa
+func (m *FileManager) nextTableFiles() sstable.TableFiles {
+	if conf.SingleFileSSTable {
+		return m.singleTableFiles(m.sstableID)
+	}
+	return m.tableFiles(m.sstableID)
+}
+
+// ensureDirs pravi direktorijume za sve delove SSTable-a ako ne postoje
+func (m *FileManager) ensureDirs() error {
+	for _, base := range append(tableDirs, singleTableDir, quarantineDir) {
+		if err := os.MkdirAll(filepath.Join(m.dir, base), 0755); err != nil {
+			return fmt.Errorf("failed to create sstable directory %s: %v", base, err)
+		}
+	}
+	// novi direktorijumi moraju biti trajni pre prvog SSTable-a u njima
+	if err := syncDir(m.dir); err != nil {
+		return fmt.Errorf("failed to sync sstable directory: %v", err)
+	}
+	return nil
 }
 
 type Manager struct {
 	blockManager *blockmanager.BlockManager
 	wal          *wal.WAL
 	memtable     memtable.MemTableInterface
+	immutables   []*memtable.ImmutableMemTable                // zapecacene tabele koje cekaju flush, od najstarije ka najnovijoj
+	walMarks     map[*memtable.ImmutableMemTable]wal.Position // polozaj poslednjeg rekorda WAL-a u svakoj zapecacenoj tabeli
+	walMark      wal.Position                                 // poslednja sacuvana low-water mark
+	walCleanup   uint64                                       // segmenti WAL-a ispod ovog broja mogu da se obrisu, 0 ako nema sta
 	cache        *cache.Cache
-	data         *sstable.Data
-	index        *sstable.Index
-	summary      *sstable.Summary
-	filter       *sstable.BloomFilter
-	mtree        *sstable.MerkleTree
+	tables       *TableRegistry
 	mfile        *FileManager
+	flusher      *flusher
+	// lock cuva memtable, immutables, tables i cache: citaoci ga drze deljeno tokom celog GET-a,
+	// a upis u memtable i objava flush-a ili kompakcije ekskluzivno
+	lock sync.RWMutex
+	// writeLock serijalizuje upise, pa se WAL i njegov buffer pool koriste iz jedne gorutine u isto vreme
+	writeLock sync.Mutex
+	closed    bool
+
+	bucket         *tokenbucket.TokenBucket
+	bucketLock     sync.Mutex // uzima se pre bucketSaveLock-a i writeLock-a (Close)
+	bucketTakes    uint64     // tokeni uzeti od poslednjeg snimka stanja bucket-a
+	bucketSeq      uint64     // redni broj poslednjeg snimka stanja bucket-a
+	bucketSaveLock sync.Mutex // uzima se pre writeLock-a; cuva bucketSavedSeq (vidi saveBucketState)
+	bucketSavedSeq uint64     // redni broj poslednjeg snimka upisanog u WAL
+	valueLock      sync.Mutex // read-modify-write struktura pod korisnickim kljucevima (vidi updateValue)
 }
 
 var conf *Config
@@ -57,226 +129,441 @@ func NewManager(memTableType memtable.MemTableType) *Manager {
 
 	bufferPool := blockmanager.NewBufferPool()
 	blockManager := blockmanager.NewBlockManager(bufferPool, conf.BlockSize, conf.BlockSize*5)
-	wal := wal.NewWal(5, blockManager)
-	mf := NewFileManager()
+	writeAheadLog := wal.NewWal(uint64(conf.WalSegmentSize), filepath.Join(conf.DataDir, "walFile"), blockManager)
+	syncInterval := time.Duration(conf.WalSyncInterval) * time.Millisecond
+	if err := writeAheadLog.SetSyncPolicy(wal.SyncMode(conf.WalSyncMode), syncInterval); err != nil {
+		panic(err)
+	}
+	groupDelay := time.Duration(conf.WalGroupCommitDelay) * time.Microsecond
+	if err := writeAheadLog.SetGroupCommit(groupDelay, conf.WalGroupCommitSize); err != nil {
+		panic(err)
+	}
+	if err := writeAheadLog.SetRecoveryMode(wal.RecoveryMode(conf.WalRecoveryMode)); err != nil {
+		panic(err)
+	}
+	mf := NewFileManager(filepath.Join(conf.DataDir, "sstable"))
+	if err := mf.ensureDirs(); err != nil {
+		panic(err)
+	}
 	// Kreiraj memtable sa izabranim tipom
-	mt := memtable.CreateMemTable(memTableType, conf.MemCapacity)
-	loadFromWAL(mt, wal)
+	mt := memtable.CreateMemTable(memTableType, conf.MemCapacity, conf.MemtableTables, conf.SkipListHeight, conf.BTreeMinDegree)
+
+	// Ucitaj SSTable-ove koji su ostali na disku od prethodnog pokretanja
+	tables, err := mf.discoverTables()
+	if err != nil {
+		panic(fmt.Sprintf("greska pri ucitavanju SSTable-ova: %v", err))
+	}
 
 	ch := cache.NewCache(conf.CacheCapacity)
 
-	dt := sstable.NewData(mf.nextFileName("DATA", "Data"), conf.BlockSize, conf.BlockSize*5)
-	idx := sstable.NewIndex(mf.nextFileName("INDEX", "Index"), nil) // za početak prazan
-	expectedElements := conf.MemCapacity * memtable.DEFAULT_NUMBER_OF_TABLES
-	bf := sstable.NewBloomFilter(expectedElements, 0.01)
-	s := sstable.NewSummary(mf.nextFileName("SUMMARY", "Summary"))
-	return &Manager{
+	manager := &Manager{
 		blockManager: blockManager,
-		wal:          wal,
+		wal:          writeAheadLog,
 		memtable:     mt,
 		cache:        ch,
-		data:         dt,
-		index:        idx,
-		summary:      s,
-		filter:       bf,
-		mtree:        nil,
+		tables:       tables,
 		mfile:        mf,
+		walMarks:     make(map[*memtable.ImmutableMemTable]wal.Position),
+		// jedna tabela memtable-a je aktivna, ostale mogu da cekaju na flush
+		flusher: newFlusher(conf.MemtableTables),
+	}
+	manager.flusher.start(manager)
+
+	// tabele koje se napune tokom ucitavanja WAL-a idu u flush kao i kod obicnog upisa
+	if err := manager.loadFromWAL(); err != nil {
+		panic(fmt.Sprintf("greska pri ucitavanju WAL-a: %v", err))
+	}
+	// stanje token bucket-a je sacuvano u samoj bazi, pa se cita tek posto su ucitani WAL i SSTable-ovi
+	if err := manager.loadRateLimiter(); err != nil {
+		panic(fmt.Sprintf("greska pri ucitavanju token bucket-a: %v", err))
 	}
+	return manager
 }
 
-// Funkcija za učitavanje memtable iz WAL-a pri startup-u - optimizovana verzija
-func loadFromWAL(mt memtable.MemTableInterface, wal *wal.WAL) {
+// loadFromWAL pri pokretanju ucitava u memtable rekorde WAL-a koji jos nisu u SSTable-ovima.
+// Segmenti ispod sacuvane low-water mark se brisu, a rekordi do oznake (ukljucujuci i nju) se preskacu.
+// Ostali se upisuju redom kojim su u WAL-u, pa kasnija verzija kljuca pobedjuje.
+// Na ostecene rekorde (npr. blok delimicno upisan pre pada) se reaguje po walRecoveryMode, vidi recoverWAL.
+func (manager *Manager) loadFromWAL() error {
 	fmt.Println("Loading memtable from WAL...")
+	mark, err := manager.wal.LoadLowWaterMark()
+	if err != nil {
+		return err
+	}
+	active, err := manager.wal.GetActiveSegment()
+	if err != nil {
+		return err
+	}
+	// oznaka iza poslednjeg segmenta ili uz prazan WAL je od WAL-a koji vise ne postoji
+	if manager.wal.GetNumberOfRecords() == 0 || mark.Segment > active {
+		mark = wal.Position{}
+	}
+	manager.walMark = mark
+	if !mark.IsZero() {
+		if err := manager.wal.DeleteSegments(mark.Segment); err != nil {
+			return err
+		}
+	}
 
 	// Reset counter da čita od početka
-	wal.ResetCounter()
+	manager.wal.ResetCounter()
 
-	// Mapa za čuvanje poslednje verzije svakog ključa
-	keyMap := make(map[string]*blockmanager.Record)
-
-	totalRecords := 0
+	totalRecords, skippedRecords := 0, 0
 	for {
-		record, hasNext := wal.NextRecord(wal.GetBlockManager())
+		pos, err := manager.wal.GetCurrentPosition()
+		if err != nil {
+			return err
+		}
+		record, hasNext := manager.wal.NextRecord(manager.wal.GetBlockManager())
 		if record == nil {
 			break // Nema više zapisa
 		}
-		totalRecords++
-		key := record.GetKey()
-
-		// Uvek uzmi poslednju verziju ključa (newer timestamp/sequence wins)
-		existingRecord, exists := keyMap[key]
-		if !exists || record.GetTimeStamp() > existingRecord.GetTimeStamp() {
-			keyMap[key] = record
+		if mark.Before(pos) {
+			if err := manager.putToMemtable(record, pos); err != nil {
+				return err
+			}
+			totalRecords++
+		} else {
+			skippedRecords++
 		}
 		if !hasNext {
 			break // Nema više zapisa
 		}
 	}
 
-	uniqueRecords := 0
-	for _, record := range keyMap {
-		mt.PutRecord(record)
-		uniqueRecords++
+	fmt.Printf("Loaded %d records from WAL into memtable, skipped %d already in SSTables\n", totalRecords, skippedRecords)
+	return manager.recoverWAL()
+}
+
+// recoverWAL obradjuje ostecenja na koja je naislo ucitavanje WAL-a, po walRecoveryMode iz configa,
+// i ispisuje sta je odbaceno
+func (manager *Manager) recoverWAL() error {
+	corruptions := manager.wal.GetCorruptions()
+	if len(corruptions) == 0 {
+		return nil
+	}
+	switch manager.wal.GetRecoveryMode() {
+	case wal.RECOVERY_FAIL:
+		return fmt.Errorf("%v (set walRecoveryMode to truncate or skip to start without the damaged part)", corruptions[0])
+	case wal.RECOVERY_SKIP:
+		for _, c := range corruptions {
+			fmt.Printf("Skipped %v: discarded %d bytes\n", c, c.Discarded)
+		}
+		return nil
 	}
 
-	fmt.Printf("Loaded %d total records from WAL, %d unique keys into memtable\n", totalRecords, uniqueRecords)
+	c := corruptions[0]
+	discarded, removed, err := manager.wal.Truncate(c)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Truncated WAL at %v: discarded %d bytes", c, discarded)
+	if len(removed) > 0 {
+		fmt.Printf(", removed segments %v", removed)
+	}
+	fmt.Println()
+
+	// novi rekordi se pisu od mesta ostecenja, pa sacuvana oznaka mora biti pre njega da bi se oni ucitali
+	// posle pada; gleda se oznaka iz fajla, jer se ona u memoriji ponistava kada je WAL do ostecenja prazan
+	manager.lock.Lock()
+	defer manager.lock.Unlock()
+	saved, err := manager.wal.LoadLowWaterMark()
+	if err != nil {
+		return err
+	}
+	if !saved.IsZero() && !saved.Before(c.Position) {
+		mark := c.Position.Previous()
+		if err := manager.wal.SaveLowWaterMark(mark); err != nil {
+			return err
+		}
+		manager.walMark = mark
+	}
+	return nil
+}
+
+// checkKeySize proverava da kljuc staje u blok zajedno sa zaglavljem rekorda i bar jednim bajtom vrednosti.
+// Svaki deo podeljenog rekorda nosi ceo kljuc, pa za duzi kljuc DivideRecord ne bi imao mesta za vrednost.
+func checkKeySize(key string) error {
+	maxKeySize := conf.BlockSize - blockmanager.RECORD_BASE_SIZE - 1
+	if uint64(len(key)) > maxKeySize {
+		return fmt.Errorf("key is %d bytes long, blockSize %d allows at most %d", len(key), conf.BlockSize, maxKeySize)
+	}
+	return nil
 }
 
 func (manager *Manager) PUT(key string, value []byte) error {
-	record := blockmanager.SetRec(0, manager.wal.GetNumberOfRecords()+1, 0, uint64(len(key)), uint64(len(value)), key, value)
+	if err := checkUserKey(key); err != nil {
+		return err
+	}
+	if err := checkKeySize(key); err != nil {
+		return err
+	}
+	if err := manager.takeToken(); err != nil {
+		return err
+	}
 
-	//Pokušaj upis u WAL i provera uspešnost
-	err := manager.wal.WriteRecord(record, manager.blockManager)
+	if err := manager.writeRecord(key, value, 0); err != nil {
+		return err
+	}
+	fmt.Println("Data written successfully")
+	return nil
+}
+
+// writeRecord upisuje novu verziju kljuca (ili tombstone) kroz WAL u memtable.
+// Upisi u WAL i memtable se serijalizuju, pa su rekordi u memtable-u istim redom kao u WAL-u.
+// Na fsync se ceka posle pustanja writeLock-a, da bi istovremeni upisi isli u isti fsync (group commit);
+// novu verziju GET moze da vidi pre nego sto je trajna, ali PUT se ne vraca dok ne bude.
+func (manager *Manager) writeRecord(key string, value []byte, tombstone uint8) error {
+	seq, err := manager.appendRecord(key, value, tombstone)
 	if err != nil {
+		return err
+	}
+	if err := manager.wal.WaitDurable(seq); err != nil {
 		return fmt.Errorf("failed to write to WAL: %v", err)
 	}
+	return nil
+}
 
-	// Nakon uspešnog WAL zapisa: Dodaj u memtable
-	manager.memtable.PutRecord(record)
-	manager.filter.Add([]byte(key))
-
-	// Ako je memtable pun → flush u Data fajl
-	if manager.memtable.IsFull() {
-		records, err := manager.memtable.Flush()
-		if err == nil && len(records) > 0 {
-			indexEntries, err := manager.data.WriteDataFile(records)
-			if err != nil {
-				return fmt.Errorf("failed to flush memtable to SSTable: %v", err)
-			}
+// appendRecord dodaje rekord u WAL i memtable pod writeLock-om i vraca redni broj rekorda u WAL-u
+func (manager *Manager) appendRecord(key string, value []byte, tombstone uint8) (uint64, error) {
+	manager.writeLock.Lock()
+	defer manager.writeLock.Unlock()
+	if manager.closed {
+		return 0, fmt.Errorf("manager is closed")
+	}
+	return manager.appendLocked(key, value, tombstone)
+}
 
-			manager.index.SetIndexEntries(indexEntries)
-			if err := manager.index.WriteToFile(); err != nil {
-				return fmt.Errorf("\nfailed to write index: %v", err)
-			}
+// appendLocked je appendRecord za pozivaoce koji vec drze writeLock
+func (manager *Manager) appendLocked(key string, value []byte, tombstone uint8) (uint64, error) {
+	record := blockmanager.SetRec(0, manager.wal.GetNumberOfRecords()+1, tombstone, uint64(len(key)), uint64(len(value)), key, value)
 
-			// ovde napraviti i summary
+	//Pokušaj upis u WAL i provera uspešnost
+	seq, err := manager.wal.AppendRecord(record, manager.blockManager)
+	if err != nil {
+		return 0, fmt.Errorf("failed to write to WAL: %v", err)
+	}
 
-			smr, err := sstable.BuildSummaryFromIndex(
-				manager.index.GetFileName(),   // uzmi index fajl koji si upravo napravio
-				manager.summary.GetFileName(), // gde da snimi summary
-				conf.SummaryStep,              // N = svaki 5. entry ide u summary (podesi po želji)
-			)
-			if err != nil {
-				return fmt.Errorf("failed to build summary: %v", err)
-			}
-			manager.summary = smr
-			if err := manager.summary.WriteToFile(); err != nil {
-				return fmt.Errorf("failed to write summary: %v", err)
-			}
+	// Nakon uspešnog WAL zapisa: Dodaj u memtable
+	if err := manager.putToMemtable(record, manager.wal.GetLastPosition()); err != nil {
+		return 0, err
+	}
 
-			//upis bloomfiltera
-			bfFile, err := os.Create(manager.mfile.nextFileName("FILTER", "Filter"))
-			if err != nil {
-				return fmt.Errorf("failed to create bloom filter file: %v", err)
-			}
-			defer bfFile.Close()
+	manager.cleanupWAL()
+	return seq, nil
+}
 
-			_, err = bfFile.Write(manager.filter.WriteBloomFilterFile())
-			if err != nil {
-				return fmt.Errorf("failed to write bloom filter: %v", err)
-			}
+// cleanupWAL brise segmente WAL-a ispod poslednje sacuvane low-water mark.
+// Flusher samo zapamti do kog segmenta moze da se brise, a brise onaj ko drzi writeLock,
+// jer WAL i njegov buffer pool koristi jedna gorutina u isto vreme.
+func (manager *Manager) cleanupWAL() {
+	manager.lock.Lock()
+	segment := manager.walCleanup
+	manager.walCleanup = 0
+	manager.lock.Unlock()
+	if segment > 0 {
+		if err := manager.wal.DeleteSegments(segment); err != nil {
+			fmt.Printf("Failed to delete WAL segments: %v\n", err)
+		}
+	}
+}
 
-			manager.mtree = sstable.CreateMerkleTree(manager.data.GetDataBlocks(memtable.DEFAULT_NUMBER_OF_TABLES, manager.data.GetFileName()))
-			manager.mtree.Serialize(manager.mfile.nextFileName("METADATA", "Metadata"))
+// putToMemtable upisuje rekord u memtable i cache u jednom koraku, pa GET vidi ili staru ili novu verziju.
+// Pune tabele se predaju flusher-u kao immutable memtable-ovi; ako flush kasni, predaja blokira
+// (bez lock-a, da citaoci i flusher mogu da nastave) dok se ne oslobodi mesto.
+// pos je polozaj rekorda u WAL-u: tabele zapecacene ovim upisom zajedno sadrze sve rekorde do njega
+// koji nisu u starijim tabelama, pa se oznaka vezuje samo za poslednju od njih (flush ide redom).
+func (manager *Manager) putToMemtable(record *blockmanager.Record, pos wal.Position) error {
+	manager.lock.Lock()
+	if err := manager.memtable.PutRecord(record); err != nil {
+		manager.lock.Unlock()
+		return fmt.Errorf("failed to write to memtable: %v", err)
+	}
+	// cache ne sme da cuva staru verziju kljuca
+	manager.cache.Put(record)
+	sealed := manager.memtable.SealFullTables()
+	manager.immutables = append(manager.immutables, sealed...)
+	if len(sealed) > 0 {
+		manager.walMarks[sealed[len(sealed)-1]] = pos
+	}
+	manager.lock.Unlock()
 
-			fmt.Println("MemTable flushed to SSTable")
-		}
+	for _, imt := range sealed {
+		manager.flusher.submit(imt)
+	}
+	return nil
+}
+
+// flushImmutable upisuje jednu zapecacenu tabelu u novu generaciju SSTable-a i objavljuje je za citanje:
+// SSTable se registruje i tabela se uklanja iz immutable memtable-ova u istom koraku
+func (manager *Manager) flushImmutable(imt *memtable.ImmutableMemTable) error {
+	table, err := sstable.CreateSSTable(manager.mfile.sstableID, manager.mfile.nextTableFiles(), imt.GetRecords(), conf.BlockSize, conf.SummaryStep)
+	if err != nil {
+		return fmt.Errorf("failed to flush memtable to SSTable: %v", err)
 	}
 	manager.mfile.sstableID += 1
 
-	manager.blockManager.EmptyBufferPool() //samo za testiranje inace se prazni sam kad se popuni
-	fmt.Println("Data written successfully")
+	manager.lock.Lock()
+	defer manager.lock.Unlock()
+	if err := manager.tables.Add(table); err != nil {
+		// tabela nije u manifestu, pa se njeni fajlovi brisu; ponovljen flush pravi novu pod sledecim ID-jem
+		if removeErr := table.Remove(); removeErr != nil {
+			fmt.Printf("Failed to remove unregistered SSTable %d: %v\n", table.GetID(), removeErr)
+		}
+		return err
+	}
+	for i, pending := range manager.immutables {
+		if pending == imt {
+			manager.immutables = append(manager.immutables[:i], manager.immutables[i+1:]...)
+			break
+		}
+	}
+	manager.flusher.flushed()
+	manager.advanceWALMark(imt)
+
+	fmt.Printf("MemTable flushed to SSTable %d\n", table.GetID())
 	return nil
 }
 
-func (manager *Manager) GET(key string) []byte {
+// advanceWALMark pomera low-water mark WAL-a na poslednji rekord flush-ovane tabele; poziva se pod lock-om.
+// Tabele se flush-uju redom kojim su zapecacene, pa su sve starije vec u SSTable-ovima.
+// Ako oznaka ne moze da se sacuva ostaje stara, sto znaci samo da ce se pri pokretanju ucitati vise WAL-a.
+func (manager *Manager) advanceWALMark(imt *memtable.ImmutableMemTable) {
+	mark, ok := manager.walMarks[imt]
+	if !ok {
+		return
+	}
+	delete(manager.walMarks, imt)
+	if err := manager.wal.SaveLowWaterMark(mark); err != nil {
+		fmt.Printf("Failed to save WAL low-water mark: %v\n", err)
+		return
+	}
+	if mark.Segment > manager.walMark.Segment {
+		manager.walCleanup = mark.Segment
+	}
+	manager.walMark = mark
+}
+
+// FlushStatus vraca stanje pozadinskog flush-a: broj tabela koje cekaju i poslednju gresku
+func (manager *Manager) FlushStatus() FlushStatus {
+	return manager.flusher.getStatus()
+}
+
+// Close cuva stanje token bucket-a, ceka da se sve zapecacene tabele upisu u SSTable-ove i zaustavlja flusher;
+// posle toga upisi vracaju gresku
+func (manager *Manager) Close() error {
+	// bucket se ne menja dok se upisuje njegovo poslednje stanje, a stariji snimci koji jos cekaju na upis se preskacu
+	manager.bucketLock.Lock()
+	defer manager.bucketLock.Unlock()
+	manager.bucketSaveLock.Lock()
+	defer manager.bucketSaveLock.Unlock()
+	manager.writeLock.Lock()
+	defer manager.writeLock.Unlock()
+	var saveErr error
+	if !manager.closed {
+		saveErr = manager.saveRateLimiter()
+	}
+	manager.closed = true
+	manager.flusher.stop()
+	manager.cleanupWAL()
+	// poslednji fsync WAL-a, pa su posle Close svi upisi na disku bez obzira na walSyncMode
+	if err := manager.wal.Close(); err != nil {
+		return err
+	}
+	if saveErr != nil {
+		return saveErr
+	}
+	return manager.FlushStatus().LastError
+}
+
+// GET vraca vrednost kljuca, nil ako kljuc ne postoji ili je obrisan
+func (manager *Manager) GET(key string) ([]byte, error) {
+	if err := checkUserKey(key); err != nil {
+		return nil, err
+	}
+	if err := manager.takeToken(); err != nil {
+		return nil, err
+	}
+
 	fmt.Printf("Searching for key: %s\n", key)
+	record, source, err := manager.find(key)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		return nil, nil
+	}
+	if record.GetTombstone() == 1 {
+		fmt.Printf("Key '%s' is deleted (tombstone found in %s)\n", key, source)
+		return nil, nil
+	}
+	fmt.Printf("Found in %s: %s = %s\n", source, key, string(record.GetValue()))
+	return record.GetValue(), nil
+}
+
+// find trazi najnoviju verziju kljuca: memtable, zapecacene tabele, cache, pa SSTable-ovi.
+// Vraca rekord (moze biti tombstone) i ime mesta na kom je nadjen; nil ako kljuc ne postoji.
+func (manager *Manager) find(key string) (*blockmanager.Record, string, error) {
+	// vise citanja radi istovremeno; upis u memtable i zamena tabela cekaju da zavrse
+	manager.lock.RLock()
+	defer manager.lock.RUnlock()
 
 	// Prvo Traži u memtable (najbrže)
 	record := manager.memtable.Find(key)
 	if record != nil {
-		if record.GetTombstone() == 1 {
-			fmt.Printf("Key '%s' is deleted (tombstone found in memtable)\n", key)
-			return nil
-		}
-		fmt.Printf("Found in memtable: %s = %s\n", key, string(record.GetValue()))
 		manager.cache.Put(record)
-		return record.GetValue()
+		return record, "memtable", nil
+	}
+
+	// Zatim u zapecacenim tabelama koje cekaju flush, od najnovije
+	record = manager.findImmutable(key)
+	if record != nil {
+		return record, "memtable", nil
 	}
 
 	// Drugo: Trazi u cache
 	record, ok := manager.cache.Get(key)
 	if ok {
-		if record.GetTombstone() == 1 {
-			fmt.Printf("Key '%s' is deleted (tombstone found in cache)\n", key)
-			return nil
-		}
-		fmt.Printf("Found in cache: %s = %s\n", key, string(record.GetValue()))
-		return record.GetValue()
+		return record, "cache", nil
 	}
 
-	//Trece: Trazi u BloomFilter
-	file, _ := os.Open(manager.mfile.nextFileName("FILTER", "Filter"))
-	manager.filter.ReadBloomFilterFile(file)
-	if manager.filter.Contains([]byte(key)) {
-		//Ako je mozda u BF, idemo dalje
-
-		cand, _ := manager.summary.Find([]byte(key))
+	//Trece: Trazi kroz SSTable-ove od najnovijeg ka najstarijem
+	record, err := manager.tables.Get(key)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read sstable: %v", err)
+	}
+	if record == nil {
+		return nil, "", nil
+	}
+	manager.cache.Put(record)
+	return record, "sstable", nil
+}
 
-		if cand == -1 {
-			// Nije u summary → ne postoji
-			return nil
-		} else {
-			//Mozda ga ima u summary udji u indeks
-			_, err := manager.index.ReadFromFile()
-			if err != nil {
-				fmt.Printf("Greska pri citanju summary fajla: %v\n", err)
-				return nil
-			} else {
-				//Trazi u index
-				indexCandidateOffset, _ := manager.index.SearchIndex([]byte(key))
-				if indexCandidateOffset == uint32(0xFFFFFFFF) {
-					return nil
-				} else {
-					//ako ga mozda ima u index, trazi u data block iz sstable data
-					record, _, err := manager.data.FindInBlock(indexCandidateOffset, []byte(key))
-					if err != nil {
-						fmt.Printf("Greska pri citanju summary fajla: %v\n", err)
-						return nil
-					} else {
-						if record == nil {
-							return nil
-						} else {
-							manager.cache.Put(record)
-							fmt.Printf("Found in sstable")
-							return record.GetValue()
-						}
-					}
-				}
-			}
+// findImmutable trazi kljuc u zapecacenim tabelama od najnovije ka najstarijoj; pozivalac drzi lock
+func (manager *Manager) findImmutable(key string) *blockmanager.Record {
+	for i := len(manager.immutables) - 1; i >= 0; i-- {
+		if record := manager.immutables[i].Find(key); record != nil {
+			return record
 		}
-
-	} else {
-		return nil
 	}
-
+	return nil
 }
 
 func (manager *Manager) DELETE(key string) error {
-	value := make([]byte, 0)
-	record := blockmanager.SetRec(0, manager.wal.GetNumberOfRecords()+1, 1, uint64(len(key)), uint64(len(value)), key, value)
-
-	// PRVO: Pokušaj upis delete marker-a u WAL i proveri uspešnost
-	err := manager.wal.WriteRecord(record, manager.blockManager)
-	if err != nil {
-		return fmt.Errorf("failed to write delete marker to WAL: %v", err)
+	if err := checkUserKey(key); err != nil {
+		return err
+	}
+	if err := checkKeySize(key); err != nil {
+		return err
+	}
+	if err := manager.takeToken(); err != nil {
+		return err
 	}
 
-	// TEK NAKON uspešnog WAL zapisa: Dodaj delete marker u memtable
-	manager.memtable.PutRecord(record)
-	manager.cache.Put(record)
-
-	manager.blockManager.EmptyBufferPool() //samo za testiranje inace se prazni sam kad se popuni
+	// delete marker je tombstone rekord bez vrednosti
+	if err := manager.writeRecord(key, make([]byte, 0), 1); err != nil {
+		return fmt.Errorf("failed to write delete marker: %v", err)
+	}
 	fmt.Println("Data deleted successfully")
 	return nil
 }
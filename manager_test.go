@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"os"
+	"project/memtable"
+	"sync"
+	"testing"
+)
+
+// TestConcurrentStress pokrece PUT, GET, DELETE i skeniranje iz vise gorutina dok flusher u pozadini
+// upisuje SSTable-ove i radi kompakciju. Svaka gorutina pise samo svoje kljuceve, pa za njih zna tacno stanje
+// i proverava ga posle svake operacije; skeneri proveravaju da su rezultati sortirani. Pokrece se sa -race.
+func TestConcurrentStress(t *testing.T) {
+	saved := *conf
+	defer func() { *conf = saved }()
+	// manager pravi fajlove u radnom direktorijumu, pa test radi u privremenom
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+	conf.MemCapacity = 50
+	conf.CompactionStrategy = CompactionSizeTiered
+	conf.CompactionMinThreshold = 2
+	conf.RateLimitCapacity = 1 << 30
+
+	const writers, scanners, ops = 6, 2, 150
+	m := NewManager(memtable.TypeSkipList)
+
+	models := make([]map[string][]byte, writers)
+	var wg sync.WaitGroup
+	errs := make(chan error, writers+scanners)
+	for w := 0; w < writers; w++ {
+		models[w] = make(map[string][]byte)
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			errs <- stressWriter(m, w, ops, models[w])
+		}(w)
+	}
+	done := make(chan struct{})
+	var scanWg sync.WaitGroup
+	for s := 0; s < scanners; s++ {
+		scanWg.Add(1)
+		go func() {
+			defer scanWg.Done()
+			errs <- stressScanner(m, done)
+		}()
+	}
+	wg.Wait()
+	close(done)
+	scanWg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := m.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	// flusher je zaustavljen, pa se registar moze citati bez lock-a
+	flushed := m.FlushStatus().Flushed
+	if flushed < 2 || m.tables.Len() >= flushed {
+		t.Fatalf("flushed %d tables into %d sstables, want flushes and compaction during the test", flushed, m.tables.Len())
+	}
+
+	// posle ponovnog otvaranja (SSTable-ovi i WAL) stanje mora biti isto
+	m = NewManager(memtable.TypeSkipList)
+	defer m.Close()
+	for w, model := range models {
+		for i := 0; i < ops; i++ {
+			key := stressKey(w, i)
+			value, err := m.GET(key)
+			if err != nil {
+				t.Fatalf("GET %s after reopen: %v", key, err)
+			}
+			if !bytes.Equal(value, model[key]) {
+				t.Fatalf("GET %s after reopen = %q, want %q", key, value, model[key])
+			}
+		}
+	}
+}
+
+func stressKey(writer, i int) string {
+	return fmt.Sprintf("w%d-%03d", writer, i)
+}
+
+// stressWriter menja kljuceve jedne gorutine i posle svake izmene proverava GET i skeniranje svog prefiksa
+func stressWriter(m *Manager, w, ops int, model map[string][]byte) error {
+	rnd := rand.New(rand.NewSource(int64(w)))
+	for op := 0; op < ops*3; op++ {
+		key := stressKey(w, rnd.Intn(ops))
+		if rnd.Intn(4) == 0 {
+			if err := m.DELETE(key); err != nil {
+				return fmt.Errorf("DELETE %s: %v", key, err)
+			}
+			delete(model, key)
+		} else {
+			value := []byte(fmt.Sprintf("%s-%d", key, op))
+			if err := m.PUT(key, value); err != nil {
+				return fmt.Errorf("PUT %s: %v", key, err)
+			}
+			model[key] = value
+		}
+
+		value, err := m.GET(key)
+		if err != nil {
+			return fmt.Errorf("GET %s: %v", key, err)
+		}
+		if !bytes.Equal(value, model[key]) {
+			return fmt.Errorf("GET %s = %q, want %q", key, value, model[key])
+		}
+
+		if op%25 == 0 {
+			records, err := m.PrefixScan(fmt.Sprintf("w%d-", w), 1, ops)
+			if err != nil {
+				return fmt.Errorf("PrefixScan w%d: %v", w, err)
+			}
+			if len(records) != len(model) {
+				return fmt.Errorf("PrefixScan w%d returned %d records, want %d", w, len(records), len(model))
+			}
+			for _, record := range records {
+				if !bytes.Equal(record.GetValue(), model[record.GetKey()]) {
+					return fmt.Errorf("PrefixScan %s = %q, want %q", record.GetKey(), record.GetValue(), model[record.GetKey()])
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// stressScanner skenira sve kljuceve dok pisci rade i proverava da su sortirani i bez duplikata
+func stressScanner(m *Manager, done chan struct{}) error {
+	for {
+		select {
+		case <-done:
+			return nil
+		default:
+		}
+		iterator, err := m.RangeIterate("w", "x")
+		if err != nil {
+			return fmt.Errorf("RangeIterate: %v", err)
+		}
+		last := ""
+		for {
+			record, ok := iterator.Next()
+			if !ok {
+				break
+			}
+			if record.GetKey() <= last {
+				iterator.Stop()
+				return fmt.Errorf("RangeIterate returned %q after %q", record.GetKey(), last)
+			}
+			last = record.GetKey()
+		}
+		err = iterator.Err()
+		iterator.Stop()
+		if err != nil {
+			return fmt.Errorf("RangeIterate: %v", err)
+		}
+	}
+}
@@ -35,7 +35,7 @@ func NewBTreeMemTable(capacity int, minDegree int, numTables int) *BTreeMemTable
 	}
 }
 
-func (bmt *BTreeMemTable) PutRecord(record *blockmanager.Record) {
+func (bmt *BTreeMemTable) PutRecord(record *blockmanager.Record) error {
 	key := record.GetKey()
 
 	// Pretraži sve tabele da vidiš da li ključ već postoji
@@ -44,7 +44,7 @@ func (bmt *BTreeMemTable) PutRecord(record *blockmanager.Record) {
 		if existingRecord != nil {
 			//Zamena recorda ukoliko vec postoji
 			bmt.btrees[i].ReplaceRecord(key, record)
-			return
+			return nil
 		}
 	}
 
@@ -57,15 +57,14 @@ func (bmt *BTreeMemTable) PutRecord(record *blockmanager.Record) {
 		}
 	}
 
-	// Ako nema aktivne tabele (sve su pune), flush i restartuj
+	// Ako nema aktivne tabele (sve su pune), pune tabele prvo moraju da se zapecate
 	if activeTableIndex == -1 {
-		bmt.Flush()
-		bmt.Clear()
-		activeTableIndex = 0 // Nakon clear-a, prva tabela postaje aktivna
+		return ErrMemTableFull
 	}
 
 	bmt.btrees[activeTableIndex].Insert(record)
 	bmt.sizes[activeTableIndex]++
+	return nil
 }
 
 // Find pronalazi record po ključu u svim B-tree tabelama
@@ -139,6 +138,30 @@ func (bmt *BTreeMemTable) Flush() ([]*blockmanager.Record, error) {
 	return outputs, nil
 }
 
+// Iterator spaja iteratore svih B-tree tabela (in-order obilazak svakog stabla)
+func (bmt *BTreeMemTable) Iterator() MemTableIterator {
+	iterators := make([]MemTableIterator, bmt.numTables)
+	for i := 0; i < bmt.numTables; i++ {
+		iterators[i] = newSliceIterator(bmt.btrees[i].GetAllRecords())
+	}
+	return newMergeIterator(iterators)
+}
+
+// SealFullTables pecati sve B-tree tabele kada su sve pune i na njihovo mesto stavlja prazne;
+// dok ima nepune tabele upisuje se u nju i ne pecati se nista
+func (bmt *BTreeMemTable) SealFullTables() []*ImmutableMemTable {
+	if !bmt.IsFull() {
+		return nil
+	}
+	sealed := make([]*ImmutableMemTable, 0, bmt.numTables)
+	for i := 0; i < bmt.numTables; i++ {
+		sealed = append(sealed, NewImmutableMemTable(bmt.btrees[i].GetAllRecords()))
+		bmt.btrees[i] = NewBTree(bmt.childCount)
+		bmt.sizes[i] = 0
+	}
+	return sealed
+}
+
 // IsFull proverava da li su SVE B-tree tabele pune (potreban flush)
 func (bmt *BTreeMemTable) IsFull() bool {
 	for i := 0; i < bmt.numTables; i++ {
@@ -28,7 +28,7 @@ func NewHashMapMemTable(capacity int, numTables int) *HashMapMemTable {
 }
 
 // PutRecord dodaje record u hash mape
-func (hmt *HashMapMemTable) PutRecord(record *blockmanager.Record) {
+func (hmt *HashMapMemTable) PutRecord(record *blockmanager.Record) error {
 	key := record.GetKey()
 
 	// Uklanjanje duplikata ako postoji
@@ -48,14 +48,13 @@ func (hmt *HashMapMemTable) PutRecord(record *blockmanager.Record) {
 		}
 	}
 
-	// Ako nema aktivne tabele (sve su pune), flush i restartuj
+	// Ako nema aktivne tabele (sve su pune), pune tabele prvo moraju da se zapecate
 	if activeTableIndex == -1 {
-		hmt.Flush()
-		hmt.Clear()
-		activeTableIndex = 0
+		return ErrMemTableFull
 	}
 
 	hmt.tables[activeTableIndex][key] = record
+	return nil
 }
 
 // Find pronalazi record po ključu u svim tabelama
@@ -123,6 +122,37 @@ func (hmt *HashMapMemTable) Flush() ([]*blockmanager.Record, error) {
 	return outputs, nil
 }
 
+// Iterator spaja iteratore svih hash mapa; kljucevi svake mape se sortiraju pri kreiranju
+func (hmt *HashMapMemTable) Iterator() MemTableIterator {
+	iterators := make([]MemTableIterator, hmt.numTables)
+	for i := 0; i < hmt.numTables; i++ {
+		records := make([]*blockmanager.Record, 0, len(hmt.tables[i]))
+		for _, record := range hmt.tables[i] {
+			records = append(records, record)
+		}
+		iterators[i] = newSliceIterator(records)
+	}
+	return newMergeIterator(iterators)
+}
+
+// SealFullTables pecati sve hash mape kada su sve pune i na njihovo mesto stavlja prazne;
+// dok ima nepune mape upisuje se u nju i ne pecati se nista
+func (hmt *HashMapMemTable) SealFullTables() []*ImmutableMemTable {
+	if !hmt.IsFull() {
+		return nil
+	}
+	sealed := make([]*ImmutableMemTable, 0, hmt.numTables)
+	for i := 0; i < hmt.numTables; i++ {
+		records := make([]*blockmanager.Record, 0, len(hmt.tables[i]))
+		for _, record := range hmt.tables[i] {
+			records = append(records, record)
+		}
+		sealed = append(sealed, NewImmutableMemTable(records))
+		hmt.tables[i] = make(map[string]*blockmanager.Record)
+	}
+	return sealed
+}
+
 // IsFull proverava da li su SVE hash mape pune (potreban flush)
 func (hmt *HashMapMemTable) IsFull() bool {
 	for i := 0; i < hmt.numTables; i++ {
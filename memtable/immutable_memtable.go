@@ -0,0 +1,50 @@
+package memtable
+
+import (
+	"errors"
+	"project/blockmanager"
+	"sort"
+)
+
+// ErrMemTableFull vraca PutRecord kada su sve tabele pune i novi kljuc nema gde da se upise
+var ErrMemTableFull = errors.New("memtable is full")
+
+// ImmutableMemTable je zapecacena (read-only) tabela memtable-a koja ceka flush u SSTable.
+// Vise ne prima upise, ali se iz nje cita dok flush ne zavrsi.
+type ImmutableMemTable struct {
+	records []*blockmanager.Record // sortirani po kljucu
+}
+
+// NewImmutableMemTable sortira rekorde po kljucu i pravi zapecacenu tabelu od njih
+func NewImmutableMemTable(records []*blockmanager.Record) *ImmutableMemTable {
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].GetKey() < records[j].GetKey()
+	})
+	return &ImmutableMemTable{records: records}
+}
+
+// Find pronalazi record po ključu binarnom pretragom, vraća nil ako ne postoji
+func (imt *ImmutableMemTable) Find(key string) *blockmanager.Record {
+	i := sort.Search(len(imt.records), func(i int) bool {
+		return imt.records[i].GetKey() >= key
+	})
+	if i < len(imt.records) && imt.records[i].GetKey() == key {
+		return imt.records[i]
+	}
+	return nil
+}
+
+// Iterator vraća iterator kroz rekorde tabele redom po ključu
+func (imt *ImmutableMemTable) Iterator() MemTableIterator {
+	return &sliceIterator{records: imt.records}
+}
+
+// GetRecords vraća sve rekorde sortirane po ključu (za SSTable kreiranje)
+func (imt *ImmutableMemTable) GetRecords() []*blockmanager.Record {
+	return imt.records
+}
+
+// GetSize vraća broj zapisa u tabeli
+func (imt *ImmutableMemTable) GetSize() int {
+	return len(imt.records)
+}
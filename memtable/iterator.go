@@ -0,0 +1,111 @@
+package memtable
+
+import (
+	"project/blockmanager"
+	"sort"
+)
+
+// MemTableIterator prolazi kroz rekorde memtable-a redom po kljucu, bez menjanja memtable-a.
+// Posle kreiranja iterator je na prvom rekordu; Seek ga pomera na prvi kljuc >= key.
+type MemTableIterator interface {
+	// Seek pozicionira iterator na prvi rekord sa kljucem >= key
+	Seek(key string)
+
+	// Next prelazi na sledeci rekord
+	Next()
+
+	// Valid vraca false kada su svi rekordi procitani
+	Valid() bool
+
+	// Record vraca trenutni rekord (samo dok je Valid true)
+	Record() *blockmanager.Record
+}
+
+// sliceIterator prolazi kroz vec sortiran niz rekorda
+type sliceIterator struct {
+	records []*blockmanager.Record
+	pos     int
+}
+
+// newSliceIterator sortira rekorde po kljucu i vraca iterator nad njima
+func newSliceIterator(records []*blockmanager.Record) *sliceIterator {
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].GetKey() < records[j].GetKey()
+	})
+	return &sliceIterator{records: records}
+}
+
+func (it *sliceIterator) Seek(key string) {
+	it.pos = sort.Search(len(it.records), func(i int) bool {
+		return it.records[i].GetKey() >= key
+	})
+}
+
+func (it *sliceIterator) Next() {
+	if it.pos < len(it.records) {
+		it.pos++
+	}
+}
+
+func (it *sliceIterator) Valid() bool {
+	return it.pos < len(it.records)
+}
+
+func (it *sliceIterator) Record() *blockmanager.Record {
+	return it.records[it.pos]
+}
+
+// mergeIterator spaja iteratore N internih tabela u jedan niz sortiran po kljucu.
+// Kljuc je u memtable-u samo u jednoj tabeli, ali ako se ipak ponovi vraca se iz ranije tabele.
+type mergeIterator struct {
+	iterators []MemTableIterator
+	current   int // indeks iteratora sa najmanjim kljucem, -1 kada su svi procitani
+}
+
+func newMergeIterator(iterators []MemTableIterator) *mergeIterator {
+	it := &mergeIterator{iterators: iterators}
+	it.pick()
+	return it
+}
+
+// pick bira iterator ciji je trenutni kljuc najmanji
+func (it *mergeIterator) pick() {
+	it.current = -1
+	for i, sub := range it.iterators {
+		if !sub.Valid() {
+			continue
+		}
+		if it.current == -1 || sub.Record().GetKey() < it.iterators[it.current].Record().GetKey() {
+			it.current = i
+		}
+	}
+}
+
+func (it *mergeIterator) Seek(key string) {
+	for _, sub := range it.iterators {
+		sub.Seek(key)
+	}
+	it.pick()
+}
+
+func (it *mergeIterator) Next() {
+	if it.current == -1 {
+		return
+	}
+	// preskoci trenutni kljuc u svim tabelama
+	key := it.Record().GetKey()
+	for _, sub := range it.iterators {
+		if sub.Valid() && sub.Record().GetKey() == key {
+			sub.Next()
+		}
+	}
+	it.pick()
+}
+
+func (it *mergeIterator) Valid() bool {
+	return it.current != -1
+}
+
+func (it *mergeIterator) Record() *blockmanager.Record {
+	return it.iterators[it.current].Record()
+}
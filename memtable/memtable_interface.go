@@ -1,9 +1,12 @@
 package memtable
 
-import "project/blockmanager"
+import (
+	"fmt"
+	"project/blockmanager"
+	"strings"
+)
 
-// Globalne konstante za sve memtable tipove
-// Ove konstante će u budućnosti biti učitane iz konfiguracionog fajla
+// Podrazumevane vrednosti za sve memtable tipove; config ih moze promeniti
 const (
 	DEFAULT_NUMBER_OF_TABLES   = 3
 	DEFAULT_CAPACITY_PER_TABLE = 4
@@ -13,8 +16,9 @@ const (
 
 // MemTableInterface definise operacije koje svaki memtable tip mora da implementira
 type MemTableInterface interface {
-	// PutRecord dodaje record u memtable, briše postojeću verziju sa istim kljucem
-	PutRecord(record *blockmanager.Record)
+	// PutRecord dodaje record u memtable, briše postojeću verziju sa istim kljucem.
+	// Ako su sve tabele pune vraća ErrMemTableFull i ništa ne upisuje.
+	PutRecord(record *blockmanager.Record) error
 
 	// Find pronalazi record po ključu, vraća nil ako ne postoji
 	Find(key string) *blockmanager.Record
@@ -24,6 +28,13 @@ type MemTableInterface interface {
 	// Flush dobavlja sadržaj memtable-a (za SSTable kreiranje)
 	Flush() ([]*blockmanager.Record, error)
 
+	// Iterator vraća iterator kroz sve tabele redom po ključu, bez pražnjenja memtable-a
+	Iterator() MemTableIterator
+
+	// SealFullTables kada su sve tabele pune vraća ih kao ImmutableMemTable i prazni ih za nove upise.
+	// Dok ima nepune tabele vraća nil: prva nepuna prima upise, a pune pre nje su read-only.
+	SealFullTables() []*ImmutableMemTable
+
 	// IsFull proverava da li je memtable popunjen i treba flush
 	IsFull() bool
 
@@ -56,17 +67,28 @@ func (mt MemTableType) String() string {
 	}
 }
 
-// CreateMemTable factory funkcija za kreiranje memtable-a
-func CreateMemTable(memTableType MemTableType, capacity int) MemTableInterface {
+// ParseMemTableType vraca tip memtable-a za ime iz configa ("skiplist", "hashmap" ili "btree", bez obzira na velika slova)
+func ParseMemTableType(name string) (MemTableType, error) {
+	for _, t := range []MemTableType{TypeSkipList, TypeHashMap, TypeBTree} {
+		if strings.EqualFold(name, t.String()) {
+			return t, nil
+		}
+	}
+	return TypeSkipList, fmt.Errorf("unknown memtable type %q (expected skiplist, hashmap or btree)", name)
+}
+
+// CreateMemTable factory funkcija za kreiranje memtable-a sa numTables tabela od po capacity rekorda;
+// skipListHeight se koristi samo za SkipList, a btreeMinDegree samo za BTree
+func CreateMemTable(memTableType MemTableType, capacity, numTables, skipListHeight, btreeMinDegree int) MemTableInterface {
 	switch memTableType {
 	case TypeSkipList:
-		return NewSkipListMemTable(capacity, DEFAULT_NUMBER_OF_TABLES)
+		return NewSkipListMemTable(capacity, numTables, skipListHeight)
 	case TypeHashMap:
-		return NewHashMapMemTable(capacity, DEFAULT_NUMBER_OF_TABLES)
+		return NewHashMapMemTable(capacity, numTables)
 	case TypeBTree:
-		return NewBTreeMemTable(capacity, DEFAULT_BTREE_MIN_DEGREE, DEFAULT_NUMBER_OF_TABLES)
+		return NewBTreeMemTable(capacity, btreeMinDegree, numTables)
 	default:
 		// Default fallback na SkipList
-		return NewSkipListMemTable(capacity, DEFAULT_NUMBER_OF_TABLES)
+		return NewSkipListMemTable(capacity, numTables, skipListHeight)
 	}
 }
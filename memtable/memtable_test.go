@@ -0,0 +1,53 @@
+package memtable
+
+import (
+	"errors"
+	"fmt"
+	"project/blockmanager"
+	"testing"
+)
+
+func testRecord(i int) *blockmanager.Record {
+	key := fmt.Sprintf("key-%02d", i)
+	return blockmanager.SetRec(0, uint64(i), 0, uint64(len(key)), 1, key, []byte("v"))
+}
+
+func TestSealAfterAllTablesFill(t *testing.T) {
+	const capacity, numTables = 2, 3
+	for _, memTableType := range []MemTableType{TypeSkipList, TypeHashMap, TypeBTree} {
+		t.Run(memTableType.String(), func(t *testing.T) {
+			mt := CreateMemTable(memTableType, capacity, numTables, DEFAULT_SKIP_LIST_HEIGHT, DEFAULT_BTREE_MIN_DEGREE)
+			for i := 0; i < capacity*numTables-1; i++ {
+				if err := mt.PutRecord(testRecord(i)); err != nil {
+					t.Fatal(err)
+				}
+				if sealed := mt.SealFullTables(); len(sealed) != 0 {
+					t.Fatalf("sealed %d tables after %d records, before all tables were full", len(sealed), i+1)
+				}
+			}
+			if err := mt.PutRecord(testRecord(capacity*numTables - 1)); err != nil {
+				t.Fatal(err)
+			}
+
+			// bez pecacenja nema mesta za nov kljuc, a postojeci moze da se izmeni
+			if err := mt.PutRecord(testRecord(capacity * numTables)); !errors.Is(err, ErrMemTableFull) {
+				t.Fatalf("PutRecord into a full memtable returned %v, want ErrMemTableFull", err)
+			}
+			if err := mt.PutRecord(testRecord(0)); err != nil {
+				t.Fatalf("PutRecord of an existing key into a full memtable: %v", err)
+			}
+
+			sealed := mt.SealFullTables()
+			if len(sealed) != numTables {
+				t.Fatalf("sealed %d tables, want %d", len(sealed), numTables)
+			}
+			total := 0
+			for _, imt := range sealed {
+				total += len(imt.GetRecords())
+			}
+			if total != capacity*numTables || mt.GetSize() != 0 {
+				t.Fatalf("sealed %d records and kept %d, want %d and 0", total, mt.GetSize(), capacity*numTables)
+			}
+		})
+	}
+}
@@ -205,3 +205,45 @@ func (s *SkipList) Flush() {
 		current = current.next
 	}
 }
+
+// skipListIterator prolazi kroz najnizi nivo skip liste, izmedju head i tail sentinela
+type skipListIterator struct {
+	list *SkipList
+	node *Node
+}
+
+func (s *SkipList) newIterator() *skipListIterator {
+	it := &skipListIterator{list: s}
+	it.Seek("")
+	return it
+}
+
+// Seek spusta se kroz nivoe do poslednjeg cvora sa kljucem < key, pa uzima sledeci na nivou 0
+func (it *skipListIterator) Seek(key string) {
+	current := it.list.head
+	for {
+		if current.next != nil && current.next.next != nil && current.next.record.GetKey() < key {
+			current = current.next
+		} else if current.below != nil {
+			current = current.below
+		} else {
+			break
+		}
+	}
+	it.node = current.next
+}
+
+func (it *skipListIterator) Next() {
+	if it.Valid() {
+		it.node = it.node.next
+	}
+}
+
+// Valid vraca false na tail sentinelu
+func (it *skipListIterator) Valid() bool {
+	return it.node != nil && it.node.next != nil
+}
+
+func (it *skipListIterator) Record() *blockmanager.Record {
+	return it.node.record
+}
@@ -9,24 +9,26 @@ type SkipListMemTable struct {
 	tables    []*SkipList
 	capacity  int
 	numTables int
+	height    int // maksimalna visina svake skip liste
 }
 
 var _ MemTableInterface = (*SkipListMemTable)(nil)
 
-func NewSkipListMemTable(capacity int, numTables int) *SkipListMemTable {
+func NewSkipListMemTable(capacity int, numTables int, height int) *SkipListMemTable {
 	tables := make([]*SkipList, numTables)
 	for i := 0; i < numTables; i++ {
-		tables[i] = NewSkipList(DEFAULT_SKIP_LIST_HEIGHT)
+		tables[i] = NewSkipList(height)
 	}
 	return &SkipListMemTable{
 		tables:    tables,
 		numTables: numTables,
 		capacity:  capacity,
+		height:    height,
 	}
 }
 
 // PutRecord dodaje record u SkipList tabele
-func (smt *SkipListMemTable) PutRecord(record *blockmanager.Record) {
+func (smt *SkipListMemTable) PutRecord(record *blockmanager.Record) error {
 	key := record.GetKey()
 
 	// Uklanjanje duplikata ako postoje
@@ -44,14 +46,13 @@ func (smt *SkipListMemTable) PutRecord(record *blockmanager.Record) {
 		}
 	}
 
-	// Ako nema aktivne tabele (sve su pune), flush i restartuj
+	// Ako nema aktivne tabele (sve su pune), pune tabele prvo moraju da se zapecate
 	if activeTableIndex == -1 {
-		smt.Flush()
-		smt.Clear()
-		activeTableIndex = 0
+		return ErrMemTableFull
 	}
 
 	smt.tables[activeTableIndex].Insert(record)
+	return nil
 }
 
 // Find pronalazi record po ključu kroz SkipList tabele
@@ -109,8 +110,8 @@ func (smt *SkipListMemTable) Flush() ([]*blockmanager.Record, error) {
 		// Preskoči head sentinel
 		node = node.next
 
-		// Dodaj sve slogove do tail sentinela
-		for node != nil && node.record != nil {
+		// Dodaj sve slogove do tail sentinela (sam sentinel se ne flushuje)
+		for node != nil && node.record != nil && node.next != nil {
 			outputs = append(outputs, node.record)
 			node = node.next
 		}
@@ -121,6 +122,33 @@ func (smt *SkipListMemTable) Flush() ([]*blockmanager.Record, error) {
 	return outputs, nil
 }
 
+// Iterator spaja iteratore svih SkipList tabela
+func (smt *SkipListMemTable) Iterator() MemTableIterator {
+	iterators := make([]MemTableIterator, smt.numTables)
+	for i := 0; i < smt.numTables; i++ {
+		iterators[i] = smt.tables[i].newIterator()
+	}
+	return newMergeIterator(iterators)
+}
+
+// SealFullTables pecati sve SkipList tabele kada su sve pune i na njihovo mesto stavlja prazne;
+// dok ima nepune tabele upisuje se u nju i ne pecati se nista
+func (smt *SkipListMemTable) SealFullTables() []*ImmutableMemTable {
+	if !smt.IsFull() {
+		return nil
+	}
+	sealed := make([]*ImmutableMemTable, 0, smt.numTables)
+	for i := 0; i < smt.numTables; i++ {
+		var records []*blockmanager.Record
+		for it := smt.tables[i].newIterator(); it.Valid(); it.Next() {
+			records = append(records, it.Record())
+		}
+		sealed = append(sealed, NewImmutableMemTable(records))
+		smt.tables[i] = NewSkipList(smt.height)
+	}
+	return sealed
+}
+
 // IsFull proverava da li su SVE tabele pune (potreban flush)
 func (smt *SkipListMemTable) IsFull() bool {
 	for i := 0; i < smt.numTables; i++ {
@@ -134,7 +162,7 @@ func (smt *SkipListMemTable) IsFull() bool {
 // Clear briše sve podatke iz SkipList tabela
 func (smt *SkipListMemTable) Clear() {
 	for i := 0; i < smt.numTables; i++ {
-		smt.tables[i] = NewSkipList(DEFAULT_SKIP_LIST_HEIGHT)
+		smt.tables[i] = NewSkipList(smt.height)
 	}
 }
 
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"project/tokenbucket"
+	"time"
+)
+
+// tokenBucketName je ime sistemskog kljuca pod kojim se cuva stanje token bucket-a
+const tokenBucketName = "token_bucket"
+
+// ErrRateLimited vracaju PUT, GET i DELETE kada je token bucket prazan
+var ErrRateLimited = errors.New("rate limited")
+
+// loadRateLimiter cita sacuvano stanje token bucket-a; ako ga nema, bucket krece pun
+func (manager *Manager) loadRateLimiter() error {
+	capacity := uint64(conf.RateLimitCapacity)
+	interval := time.Duration(conf.RateLimitInterval) * time.Second
+
+	state, err := manager.getSystem(tokenBucketName)
+	if err != nil {
+		return err
+	}
+	if state == nil {
+		manager.bucket = tokenbucket.NewTokenBucket(capacity, interval)
+		return nil
+	}
+	manager.bucket, err = tokenbucket.Deserialize(state, capacity, interval)
+	return err
+}
+
+// BUCKET_SAVE_EVERY je broj uzetih tokena posle kog se stanje bucket-a upisuje u WAL. Stanje se upisuje i kada
+// se bucket napuni i pri Close, pa posle pada bucket moze da izda najvise BUCKET_SAVE_EVERY-1 tokena vise nego sto
+// dozvoljava kapacitet (oni uzeti posle poslednjeg trajnog upisa), a vreme punjenja ostaje sacuvano.
+const BUCKET_SAVE_EVERY = 16
+
+// takeToken uzima token za jedan zahtev i svaki BUCKET_SAVE_EVERY-ti put (ili posle punjenja) upisuje stanje bucket-a.
+// Pod bucketLock-om se samo menja bucket i uzima snimak stanja; upis u WAL i cekanje na fsync su posle,
+// pa GET-ovi ne cekaju jedan drugog dok se stanje upisuje.
+func (manager *Manager) takeToken() error {
+	manager.bucketLock.Lock()
+	now := time.Now()
+	refilled := manager.bucket.Refill(now)
+	if !manager.bucket.Take(now) {
+		manager.bucketLock.Unlock()
+		return ErrRateLimited
+	}
+	manager.bucketTakes++
+	if !refilled && manager.bucketTakes < BUCKET_SAVE_EVERY {
+		manager.bucketLock.Unlock()
+		return nil
+	}
+	manager.bucketTakes = 0
+	manager.bucketSeq++
+	seq, state := manager.bucketSeq, manager.bucket.Serialize()
+	manager.bucketLock.Unlock()
+
+	return manager.saveBucketState(seq, state)
+}
+
+// saveBucketState upisuje snimak stanja bucket-a sa rednim brojem seq i ceka da bude trajan.
+// Snimci se upisuju redom: snimak stariji od vec upisanog se preskace, da ne bi pregazio novije stanje.
+func (manager *Manager) saveBucketState(seq uint64, state []byte) error {
+	manager.bucketSaveLock.Lock()
+	if seq <= manager.bucketSavedSeq {
+		manager.bucketSaveLock.Unlock()
+		return nil
+	}
+	walSeq, err := manager.appendRecord(systemKey(tokenBucketName), state, 0)
+	if err == nil {
+		manager.bucketSavedSeq = seq
+	}
+	manager.bucketSaveLock.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to save token bucket: %v", err)
+	}
+	if err := manager.wal.WaitDurable(walSeq); err != nil {
+		return fmt.Errorf("failed to save token bucket: %v", err)
+	}
+	return nil
+}
+
+// saveRateLimiter upisuje trenutno stanje bucket-a u WAL kao najnoviji snimak;
+// poziva se iz Close, pod bucketLock-om, bucketSaveLock-om i writeLock-om
+func (manager *Manager) saveRateLimiter() error {
+	manager.bucketSeq++
+	if _, err := manager.appendLocked(systemKey(tokenBucketName), manager.bucket.Serialize(), 0); err != nil {
+		return fmt.Errorf("failed to save token bucket: %v", err)
+	}
+	manager.bucketSavedSeq = manager.bucketSeq
+	return nil
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"errors"
+	"project/memtable"
+	"testing"
+)
+
+func TestTokenBucketStateSurvivesCrash(t *testing.T) {
+	useTempDir(t)
+	conf.RateLimitCapacity = 100
+	conf.RateLimitInterval = 3600
+
+	m := NewManager(memtable.TypeSkipList)
+	for i := 0; i < BUCKET_SAVE_EVERY+5; i++ {
+		if _, err := m.GET("key"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// pad: WAL se ucitava bez Close, pa vazi stanje iz poslednjeg snimka
+	crashed := NewManager(memtable.TypeSkipList)
+	if got, want := crashed.bucket.GetTokens(), uint64(100-BUCKET_SAVE_EVERY); got != want {
+		t.Fatalf("bucket has %d tokens after a crash, want %d", got, want)
+	}
+	if err := crashed.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Close upisuje tacno stanje
+	m = NewManager(memtable.TypeSkipList)
+	if got, want := m.bucket.GetTokens(), uint64(100-BUCKET_SAVE_EVERY); got != want {
+		t.Fatalf("bucket has %d tokens after reopen, want %d", got, want)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := m.GET("key"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+	m = NewManager(memtable.TypeSkipList)
+	defer m.Close()
+	if got, want := m.bucket.GetTokens(), uint64(100-BUCKET_SAVE_EVERY-3); got != want {
+		t.Fatalf("bucket has %d tokens after Close, want %d", got, want)
+	}
+
+	// prazan bucket odbija zahteve
+	for i := uint64(0); i < 100-BUCKET_SAVE_EVERY-3; i++ {
+		if _, err := m.GET("key"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := m.GET("key"); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("GET on an empty bucket returned %v, want ErrRateLimited", err)
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"project/blockmanager"
+)
+
+// RangeScan vraca stranicu (pageNumber krece od 1) zivih rekorda sa kljucem u [minKey, maxKey],
+// sortiranih po kljucu. Memtable i svi SSTable-ovi se spajaju kao kod GET-a: za svaki kljuc
+// vazi verzija iz najnovijeg izvora, a obrisani kljucevi se ne vracaju.
+func (manager *Manager) RangeScan(minKey, maxKey string, pageNumber, pageSize int) ([]*blockmanager.Record, error) {
+	if pageNumber < 1 || pageSize < 1 {
+		return nil, fmt.Errorf("page number and page size must be positive")
+	}
+	iterator, err := manager.RangeIterate(minKey, maxKey)
+	if err != nil {
+		return nil, err
+	}
+	return collectPage(iterator, pageNumber, pageSize)
+}
+
+// PrefixScan vraca stranicu (pageNumber krece od 1) zivih rekorda ciji kljuc pocinje sa prefix,
+// sortiranih po kljucu, sa istim spajanjem izvora kao RangeScan.
+func (manager *Manager) PrefixScan(prefix string, pageNumber, pageSize int) ([]*blockmanager.Record, error) {
+	if pageNumber < 1 || pageSize < 1 {
+		return nil, fmt.Errorf("page number and page size must be positive")
+	}
+	iterator, err := manager.PrefixIterate(prefix)
+	if err != nil {
+		return nil, err
+	}
+	return collectPage(iterator, pageNumber, pageSize)
+}
+
+// collectPage preskace rekorde prethodnih stranica i vraca trazenu stranicu, praznu ako ne postoji.
+// Iterator se zaustavlja cim je stranica procitana.
+func collectPage(iterator *ScanIterator, pageNumber, pageSize int) ([]*blockmanager.Record, error) {
+	defer iterator.Stop()
+
+	skip := (pageNumber - 1) * pageSize
+	page := make([]*blockmanager.Record, 0, pageSize)
+	for len(page) < pageSize {
+		record, ok := iterator.Next()
+		if !ok {
+			break
+		}
+		if skip > 0 {
+			skip--
+			continue
+		}
+		page = append(page, record)
+	}
+	if err := iterator.Err(); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"project/simhash"
+)
+
+// SimHashPut racuna SimHash otisak teksta i cuva ga pod kljucem key (postojeci otisak se menja)
+func (manager *Manager) SimHashPut(key string, text string) error {
+	if err := manager.takeToken(); err != nil {
+		return err
+	}
+	fingerprint := simhash.Fingerprint(text)
+	return manager.updateValue(key, func(value []byte) ([]byte, error) {
+		// menja se samo otisak, ne i vrednost drugog tipa
+		if value != nil {
+			if _, err := decodeTyped(ValueSimHash, key, value); err != nil {
+				return nil, err
+			}
+		}
+		return encodeTyped(ValueSimHash, simhash.Serialize(fingerprint)), nil
+	})
+}
+
+// SimHashGet vraca otisak sacuvan pod kljucem key
+func (manager *Manager) SimHashGet(key string) (uint64, error) {
+	if err := manager.takeToken(); err != nil {
+		return 0, err
+	}
+	return manager.readFingerprint(key)
+}
+
+// SimHashDistance vraca Hamming rastojanje izmedju otisaka sacuvanih pod kljucevima key1 i key2
+func (manager *Manager) SimHashDistance(key1 string, key2 string) (int, error) {
+	if err := manager.takeToken(); err != nil {
+		return 0, err
+	}
+	a, err := manager.readFingerprint(key1)
+	if err != nil {
+		return 0, err
+	}
+	b, err := manager.readFingerprint(key2)
+	if err != nil {
+		return 0, err
+	}
+	return simhash.HammingDistance(a, b), nil
+}
+
+// SimHashDelete brise otisak sacuvan pod kljucem key
+func (manager *Manager) SimHashDelete(key string) error {
+	if err := manager.takeToken(); err != nil {
+		return err
+	}
+	return manager.updateValue(key, func(value []byte) ([]byte, error) {
+		if _, err := decodeTyped(ValueSimHash, key, value); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	})
+}
+
+// readFingerprint cita i deserijalizuje otisak sacuvan pod kljucem key
+func (manager *Manager) readFingerprint(key string) (uint64, error) {
+	value, err := manager.getValue(key)
+	if err != nil {
+		return 0, err
+	}
+	data, err := decodeTyped(ValueSimHash, key, value)
+	if err != nil {
+		return 0, err
+	}
+	fingerprint, err := simhash.Deserialize(data)
+	if err != nil {
+		return 0, fmt.Errorf("simhash %q is corrupted: %v", key, err)
+	}
+	return fingerprint, nil
+}
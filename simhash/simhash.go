@@ -0,0 +1,77 @@
+package simhash
+
+import (
+	"crypto/md5"
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+	"strings"
+	"unicode"
+)
+
+// FINGERPRINT_SIZE je velicina serijalizovanog otiska u bajtovima
+const FINGERPRINT_SIZE = 8
+
+// Tokenize deli tekst na reci: sve sto nije slovo ili cifra je separator, a reci se prebacuju u mala slova
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Weigh vraca tezinu svake reci, odnosno broj njenih pojavljivanja u tekstu
+func Weigh(tokens []string) map[string]int {
+	weights := make(map[string]int)
+	for _, token := range tokens {
+		weights[token]++
+	}
+	return weights
+}
+
+func hash(data []byte) uint64 {
+	sum := md5.Sum(data)
+	return binary.BigEndian.Uint64(sum[:8])
+}
+
+// Fingerprint racuna 64-bitni SimHash teksta. Za svaki bit se sabiraju tezine reci
+// ciji hash ima taj bit postavljen i oduzimaju tezine ostalih; bit otiska je 1 ako je zbir pozitivan.
+// Slicni tekstovi dobijaju otiske koji se razlikuju u malo bita.
+func Fingerprint(text string) uint64 {
+	var sums [64]int
+	for token, weight := range Weigh(Tokenize(text)) {
+		h := hash([]byte(token))
+		for i := 0; i < 64; i++ {
+			if h&(1<<i) != 0 {
+				sums[i] += weight
+			} else {
+				sums[i] -= weight
+			}
+		}
+	}
+
+	var fingerprint uint64
+	for i, sum := range sums {
+		if sum > 0 {
+			fingerprint |= 1 << i
+		}
+	}
+	return fingerprint
+}
+
+// HammingDistance vraca broj bita u kojima se dva otiska razlikuju
+func HammingDistance(a uint64, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// Serialize vraca otisak kao FINGERPRINT_SIZE bajtova (little endian)
+func Serialize(fingerprint uint64) []byte {
+	return binary.LittleEndian.AppendUint64(make([]byte, 0, FINGERPRINT_SIZE), fingerprint)
+}
+
+// Deserialize cita otisak zapisan sa Serialize
+func Deserialize(data []byte) (uint64, error) {
+	if len(data) != FINGERPRINT_SIZE {
+		return 0, fmt.Errorf("simhash fingerprint has %d bytes, expected %d", len(data), FINGERPRINT_SIZE)
+	}
+	return binary.LittleEndian.Uint64(data), nil
+}
@@ -0,0 +1,83 @@
+package simhash
+
+import (
+	"testing"
+)
+
+func TestTokenizeAndWeigh(t *testing.T) {
+	tokens := Tokenize("Ana, ana i  MARKO-42!")
+	want := []string{"ana", "ana", "i", "marko", "42"}
+	if len(tokens) != len(want) {
+		t.Fatalf("Tokenize = %q, want %q", tokens, want)
+	}
+	for i := range want {
+		if tokens[i] != want[i] {
+			t.Fatalf("Tokenize = %q, want %q", tokens, want)
+		}
+	}
+	weights := Weigh(tokens)
+	if weights["ana"] != 2 || weights["marko"] != 1 || len(weights) != 4 {
+		t.Fatalf("Weigh = %v", weights)
+	}
+}
+
+func TestHammingDistance(t *testing.T) {
+	cases := []struct {
+		a, b uint64
+		want int
+	}{
+		{0, 0, 0},
+		{0, ^uint64(0), 64},
+		{0b1011, 0b0110, 3},
+		{1 << 63, 1, 2},
+	}
+	for _, c := range cases {
+		if got := HammingDistance(c.a, c.b); got != c.want {
+			t.Errorf("HammingDistance(%b, %b) = %d, want %d", c.a, c.b, got, c.want)
+		}
+	}
+}
+
+func TestSimilarTextsAreCloser(t *testing.T) {
+	text := "the quick brown fox jumps over the lazy dog near the old river bank at dawn"
+	similar := "the quick brown fox jumps over the lazy cat near the old river bank at dawn"
+	different := "stock markets closed higher today as investors weighed interest rate news"
+
+	if Fingerprint(text) != Fingerprint("The QUICK brown fox, jumps over the lazy dog near the old river bank at dawn.") {
+		t.Fatal("case and punctuation changed the fingerprint")
+	}
+	near := HammingDistance(Fingerprint(text), Fingerprint(similar))
+	far := HammingDistance(Fingerprint(text), Fingerprint(different))
+	if near >= far {
+		t.Fatalf("distance to a similar text is %d, to a different one %d", near, far)
+	}
+}
+
+func TestSerializeRoundTrip(t *testing.T) {
+	fingerprint := Fingerprint("round trip")
+	data := Serialize(fingerprint)
+	if len(data) != FINGERPRINT_SIZE {
+		t.Fatalf("Serialize returned %d bytes, want %d", len(data), FINGERPRINT_SIZE)
+	}
+	restored, err := Deserialize(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored != fingerprint {
+		t.Fatalf("Deserialize = %x, want %x", restored, fingerprint)
+	}
+}
+
+func TestDeserializeCorrupted(t *testing.T) {
+	data := Serialize(42)
+	cases := map[string][]byte{
+		"empty": nil,
+		"short": data[:FINGERPRINT_SIZE-1],
+		"long":  append(append([]byte(nil), data...), 0),
+	}
+	for name, corrupted := range cases {
+		if _, err := Deserialize(corrupted); err == nil {
+			t.Errorf("%s: Deserialize succeeded", name)
+		}
+	}
+}
@@ -10,7 +10,7 @@ import (
 // IndexEntry predstavlja sparse index entry (prvi key u data bloku)
 type IndexEntry struct {
 	Key    []byte
-	Offset uint32 // offset unutar bloka (ovde koristimo 0 za pocetak bloka)
+	Offset uint64 // bajt offset pocetka data bloka u data fajlu
 }
 
 // Data predstavlja glavni segment SSTable fajla
@@ -19,6 +19,7 @@ type Data struct {
 	blockSize    uint64
 	blockManager *blockmanager.BlockManager
 	numRecords   uint64
+	section      section // deo fajla sa data blokovima (uvek od pocetka fajla)
 }
 
 // Konstruktor
@@ -32,6 +33,7 @@ func NewData(fileName string, blockSize uint64, poolSize uint64) *Data {
 			poolSize,
 		),
 		numRecords: 0,
+		section:    wholeFile,
 	}
 }
 
@@ -96,7 +98,6 @@ func (d *Data) WriteDataFile(records []*blockmanager.Record) (indexEntries []Ind
 			}
 			curRecords = append(curRecords, rec)
 			curBlockBytes += rSize
-			//indexEntries = append(indexEntries, IndexEntry{Key: firstKeyInBlock, Offset: currentBlockNum})
 			continue
 		}
 
@@ -105,7 +106,7 @@ func (d *Data) WriteDataFile(records []*blockmanager.Record) (indexEntries []Ind
 			if len(curRecords) > 0 {
 				// upiši trenutni blok
 				d.blockManager.WriteBlock(curRecords, d.fileName, uint64(currentBlockNum))
-				indexEntries = append(indexEntries, IndexEntry{Key: firstKeyInBlock, Offset: currentBlockNum})
+				indexEntries = append(indexEntries, IndexEntry{Key: firstKeyInBlock, Offset: d.blockOffset(currentBlockNum)})
 				currentBlockNum++
 			}
 
@@ -129,7 +130,7 @@ func (d *Data) WriteDataFile(records []*blockmanager.Record) (indexEntries []Ind
 			if curBlockBytes+partSize > d.blockSize {
 				// zatvori trenutni blok
 				d.blockManager.WriteBlock(curRecords, d.fileName, uint64(currentBlockNum))
-				indexEntries = append(indexEntries, IndexEntry{Key: firstKeyInBlock, Offset: currentBlockNum})
+				indexEntries = append(indexEntries, IndexEntry{Key: firstKeyInBlock, Offset: d.blockOffset(currentBlockNum)})
 				currentBlockNum++
 				curRecords = curRecords[:0]
 				curBlockBytes = 0
@@ -147,60 +148,96 @@ func (d *Data) WriteDataFile(records []*blockmanager.Record) (indexEntries []Ind
 	// upiši poslednji data blok
 	if len(curRecords) > 0 {
 		d.blockManager.WriteBlock(curRecords, d.fileName, uint64(currentBlockNum))
-		indexEntries = append(indexEntries, IndexEntry{Key: firstKeyInBlock, Offset: currentBlockNum})
+		indexEntries = append(indexEntries, IndexEntry{Key: firstKeyInBlock, Offset: d.blockOffset(currentBlockNum)})
 		currentBlockNum++
 	}
 
 	return indexEntries, nil
 }
 
-// ReadDataFile učitava ceo blok iz .data fajla
-func (d *Data) ReadDataFile(blockNum uint32) ([]*blockmanager.Record, error) {
+// blockOffset vraca bajt offset bloka u data fajlu (blokovi krecu od 1, posle hedera)
+func (d *Data) blockOffset(blockNum uint32) uint64 {
+	return uint64(blockNum-1)*d.blockSize + uint64(blockmanager.HEADER_SIZE)
+}
+
+// ReadDataFile učitava ceo blok koji počinje na datom bajt offsetu .data fajla
+func (d *Data) ReadDataFile(offset uint64) ([]*blockmanager.Record, error) {
 	f, err := os.Open(d.fileName)
 	if err != nil {
 		return nil, err
 	}
 	defer f.Close()
 
-	// izračunaj offset bloka u fajlu (preskoči header)
-	offset := int64(blockNum-1)*int64(d.blockSize) + int64(blockmanager.HEADER_SIZE)
+	return d.readBlock(f, offset)
+}
+
+// readBlock čita i deserijalizuje jedan blok sa datog offseta iz vec otvorenog data fajla
+func (d *Data) readBlock(f *os.File, offset uint64) ([]*blockmanager.Record, error) {
 	buf := make([]byte, d.blockSize)
 
-	_, err = f.ReadAt(buf, offset)
+	_, err := f.ReadAt(buf, int64(offset))
 	if err != nil {
 		return nil, err
 	}
 
-	// deserijalizuj sve rekorde u tom bloku
+	return d.deserializeBlock(buf, offset)
+}
+
+// deserializeBlock vraca sve rekorde jednog data bloka. Ostecen rekord je greska: SSTable se ne popravlja
+// kao WAL, pa bi preskakanje od GET-a napravilo "kljuc ne postoji", a od kompakcije gubitak podataka.
+func (d *Data) deserializeBlock(buf []byte, offset uint64) ([]*blockmanager.Record, error) {
 	records := make([]*blockmanager.Record, 0)
 	i := 0
 	for i < len(buf) {
 		rec, errCode := blockmanager.Deserialize(buf[i:])
-		if errCode != 0 || rec == nil {
+		if errCode == blockmanager.DESERIALIZE_CORRUPT {
+			return nil, fmt.Errorf("corrupt record in %s, data block at offset %d, byte %d", d.fileName, offset, i)
+		}
+		if errCode != blockmanager.DESERIALIZE_OK || rec == nil {
 			break
 		}
 		records = append(records, rec)
 		i += int(rec.GetRecordSize())
 	}
-
 	return records, nil
 }
 
-// FindInBlock pretražuje ključ unutar datog bloka -- ne target nego key
-func (d *Data) FindInBlock(blockNum uint32, target []byte) (*blockmanager.Record, bool, error) {
-	records, err := d.ReadDataFile(blockNum)
+// FindInBlock pretražuje ključ unutar bloka na datom offsetu -- ne target nego key
+func (d *Data) FindInBlock(offset uint64, target []byte) (*blockmanager.Record, bool, error) {
+	records, err := d.ReadDataFile(offset)
 	if err != nil {
 		return nil, false, err
 	}
 
 	for _, rec := range records {
 		if rec.GetKey() == string(target) {
+			if rec.GetRecordType() == 1 {
+				// rekord je podeljen na vise blokova, ostali delovi su na pocetku sledecih blokova
+				return d.readDividedRecord(offset, rec)
+			}
 			return rec, true, nil
 		}
 	}
 	return nil, false, nil
 }
 
+// readDividedRecord cita preostale delove rekorda koji pocinje u bloku na datom offsetu i spaja ih
+func (d *Data) readDividedRecord(offset uint64, first *blockmanager.Record) (*blockmanager.Record, bool, error) {
+	parts := []*blockmanager.Record{first}
+	for parts[len(parts)-1].GetRecordType() != 3 {
+		offset += d.blockSize
+		records, err := d.ReadDataFile(offset)
+		if err != nil {
+			return nil, false, fmt.Errorf("missing part of divided record (key=%s): %v", first.GetKey(), err)
+		}
+		if len(records) == 0 || records[0].GetKey() != first.GetKey() {
+			return nil, false, fmt.Errorf("missing part of divided record (key=%s)", first.GetKey())
+		}
+		parts = append(parts, records[0])
+	}
+	return blockmanager.MergeRecordParts(parts), true, nil
+}
+
 func (d *Data) GetDataBlocks(numberOfBlocks uint64, filename string) []*blockmanager.Block {
 	blocks := make([]*blockmanager.Block, 0)
 	for i := 1; i < int(numberOfBlocks); i++ {
@@ -210,11 +247,11 @@ func (d *Data) GetDataBlocks(numberOfBlocks uint64, filename string) []*blockman
 }
 
 func (d *Data) ReadAllDataBlocks() ([][]*blockmanager.Record, error) {
-	f, err := os.Open(d.fileName)
+	file, f, err := openSection(d.fileName, d.section)
 	if err != nil {
 		return nil, err
 	}
-	defer f.Close()
+	defer file.Close()
 
 	// preskoči header
 	_, err = f.Seek(int64(blockmanager.HEADER_SIZE), io.SeekStart)
@@ -224,10 +261,11 @@ func (d *Data) ReadAllDataBlocks() ([][]*blockmanager.Record, error) {
 
 	var allBlocks [][]*blockmanager.Record
 
-	buf := make([]byte, d.blockSize)
 	blockNum := 1
 
 	for {
+		// novi bafer za svaki blok, jer deserijalizovani rekordi pokazuju na njegove bajtove
+		buf := make([]byte, d.blockSize)
 		n, err := f.Read(buf)
 		if err == io.EOF {
 			break // kraj fajla
@@ -240,15 +278,9 @@ func (d *Data) ReadAllDataBlocks() ([][]*blockmanager.Record, error) {
 		}
 
 		// deserijalizuj sve rekorde iz ovog bloka
-		records := make([]*blockmanager.Record, 0)
-		i := 0
-		for i < n {
-			rec, errCode := blockmanager.Deserialize(buf[i:])
-			if errCode != 0 || rec == nil {
-				break
-			}
-			records = append(records, rec)
-			i += int(rec.GetRecordSize())
+		records, err := d.deserializeBlock(buf[:n], d.blockOffset(uint32(blockNum)))
+		if err != nil {
+			return nil, err
 		}
 
 		allBlocks = append(allBlocks, records)
@@ -257,3 +289,54 @@ func (d *Data) ReadAllDataBlocks() ([][]*blockmanager.Record, error) {
 
 	return allBlocks, nil
 }
+
+// ReadAllRecords vraca sve rekorde iz data fajla redom, sa spojenim podeljenim rekordima
+func (d *Data) ReadAllRecords() ([]*blockmanager.Record, error) {
+	blocks, err := d.ReadAllDataBlocks()
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]*blockmanager.Record, 0)
+	assembler := &recordAssembler{}
+	for _, block := range blocks {
+		for _, rec := range block {
+			if whole := assembler.add(rec); whole != nil {
+				records = append(records, whole)
+			}
+		}
+	}
+	if assembler.parts != nil {
+		return nil, fmt.Errorf("divided record is missing its last part (key=%s)", assembler.parts[0].GetKey())
+	}
+	return records, nil
+}
+
+// recordAssembler spaja delove podeljenih rekorda dok se blokovi citaju redom
+type recordAssembler struct {
+	parts []*blockmanager.Record
+}
+
+// add vraca ceo rekord kada je kompletan, inace nil.
+// Delovi ciji prvi deo nije procitan (citanje je pocelo usred rekorda) se preskacu.
+func (a *recordAssembler) add(rec *blockmanager.Record) *blockmanager.Record {
+	switch rec.GetRecordType() {
+	case 1:
+		a.parts = []*blockmanager.Record{rec}
+		return nil
+	case 2:
+		if a.parts != nil {
+			a.parts = append(a.parts, rec)
+		}
+		return nil
+	case 3:
+		if a.parts == nil {
+			return nil
+		}
+		parts := append(a.parts, rec)
+		a.parts = nil
+		return blockmanager.MergeRecordParts(parts)
+	default:
+		return rec
+	}
+}
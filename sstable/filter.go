@@ -1,37 +1,13 @@
 package sstable
 
 import (
-	"crypto/md5"
 	"encoding/binary"
+	"fmt"
 	"math"
 	"os"
-	"time"
+	"project/hashfunc"
 )
 
-// HashWithSeed je hash funkcija sa seed vrednoscu
-type HashWithSeed struct {
-	Seed []byte
-}
-
-func (h HashWithSeed) Hash(data []byte) uint64 {
-	fn := md5.New()
-	fn.Write(append(data, h.Seed...))
-	return binary.BigEndian.Uint64(fn.Sum(nil))
-}
-
-// Kreira k razlicitih hash funkcija sa razlicitim seed-ovima
-func CreateHashFunctions(k uint32) []HashWithSeed {
-	h := make([]HashWithSeed, k)
-	ts := uint32(time.Now().Unix())
-	for i := uint32(0); i < k; i++ {
-		seed := make([]byte, 4)
-		binary.BigEndian.PutUint32(seed, ts+i)
-		hfn := HashWithSeed{Seed: seed}
-		h[i] = hfn
-	}
-	return h
-}
-
 // Izracunava optimalnu velicinu bit niza (m)
 func CalculateM(expectedElements int, falsePositiveRate float64) uint {
 	return uint(math.Ceil(float64(expectedElements) *
@@ -46,7 +22,7 @@ func CalculateK(expectedElements int, m uint) uint {
 
 // BloomFilter struktura
 type BloomFilter struct {
-	HashFunctions []HashWithSeed
+	HashFunctions []hashfunc.HashWithSeed
 	BitArray      []bool
 	m             uint // velicina bit niza
 	k             uint // broj hash funkcija
@@ -57,7 +33,7 @@ func NewBloomFilter(expectedElements int, falsePositiveRate float64) *BloomFilte
 	m := CalculateM(expectedElements, falsePositiveRate)
 	k := CalculateK(expectedElements, m)
 	return &BloomFilter{
-		HashFunctions: CreateHashFunctions(uint32(k)),
+		HashFunctions: hashfunc.CreateHashFunctions(uint32(k)),
 		BitArray:      make([]bool, m),
 		m:             m,
 		k:             k,
@@ -156,13 +132,44 @@ func (b *BloomFilter) ReadBloomFilterFile(file *os.File) error {
 	if err != nil {
 		return err
 	}
-	b.HashFunctions = make([]HashWithSeed, b.k)
+	b.HashFunctions = make([]hashfunc.HashWithSeed, b.k)
 	for i := 0; i < int(b.k); i++ {
 		seed := make([]byte, 4)
 		copy(seed, hashBytes[i*4:(i+1)*4])
-		hfn := HashWithSeed{Seed: seed}
+		hfn := hashfunc.HashWithSeed{Seed: seed}
 		b.HashFunctions[i] = hfn
 	}
 
 	return nil
 }
+
+// ReadBloomFilter deserijalizuje filter iz niza bajtova u formatu WriteBloomFilterFile
+func ReadBloomFilter(data []byte) (*BloomFilter, error) {
+	if len(data) < 16 {
+		return nil, fmt.Errorf("bloom filter data is too short")
+	}
+	m := binary.LittleEndian.Uint64(data)
+	k := binary.LittleEndian.Uint64(data[8:])
+	// provera bez mnozenja da pokvareni m i k ne bi prekoracili uint64
+	rest := uint64(len(data) - 16)
+	if m == 0 || m > rest || (rest-m)%4 != 0 || (rest-m)/4 != k {
+		return nil, fmt.Errorf("bloom filter data has %d bytes, expected %d", len(data), 16+m+4*k)
+	}
+
+	b := &BloomFilter{
+		BitArray:      make([]bool, m),
+		HashFunctions: make([]hashfunc.HashWithSeed, k),
+		m:             uint(m),
+		k:             uint(k),
+	}
+	for i := range b.BitArray {
+		b.BitArray[i] = data[16+i] != 0
+	}
+	seeds := data[16+m:]
+	for i := range b.HashFunctions {
+		seed := make([]byte, 4)
+		copy(seed, seeds[i*4:(i+1)*4])
+		b.HashFunctions[i] = hashfunc.HashWithSeed{Seed: seed}
+	}
+	return b, nil
+}
@@ -0,0 +1,61 @@
+package sstable
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestReadBloomFilterRoundTrip(t *testing.T) {
+	filter := NewBloomFilter(100, 0.01)
+	for i := 0; i < 100; i++ {
+		filter.Add([]byte(fmt.Sprintf("key-%d", i)))
+	}
+	restored, err := ReadBloomFilter(filter.WriteBloomFilterFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.m != filter.m || restored.k != filter.k {
+		t.Fatalf("restored filter has m=%d k=%d, want m=%d k=%d", restored.m, restored.k, filter.m, filter.k)
+	}
+	for i := 0; i < 100; i++ {
+		if !restored.Contains([]byte(fmt.Sprintf("key-%d", i))) {
+			t.Fatalf("restored filter does not contain key-%d", i)
+		}
+	}
+	// isti seed-ovi daju iste odgovore i za kljuceve koji nisu dodati
+	for i := 0; i < 1000; i++ {
+		key := []byte(fmt.Sprintf("other-%d", i))
+		if restored.Contains(key) != filter.Contains(key) {
+			t.Fatalf("restored filter answers differently for %s", key)
+		}
+	}
+}
+
+func TestReadBloomFilterCorrupted(t *testing.T) {
+	data := NewBloomFilter(10, 0.1).WriteBloomFilterFile()
+	m := binary.LittleEndian.Uint64(data)
+	k := binary.LittleEndian.Uint64(data[8:])
+	withHeader := func(m, k uint64) []byte {
+		corrupted := append([]byte(nil), data...)
+		binary.LittleEndian.PutUint64(corrupted, m)
+		binary.LittleEndian.PutUint64(corrupted[8:], k)
+		return corrupted
+	}
+	cases := map[string][]byte{
+		"empty":      nil,
+		"short":      data[:15],
+		"zero width": withHeader(0, k),
+		"truncated":  data[:len(data)-1],
+		"extra":      append(append([]byte(nil), data...), 0),
+		"wrong k":    withHeader(m, k+1),
+		"huge width": withHeader(math.MaxUint64, k),
+		"huge k":     withHeader(m, math.MaxUint64),
+	}
+	for name, corrupted := range cases {
+		if _, err := ReadBloomFilter(corrupted); err == nil {
+			t.Errorf("%s: ReadBloomFilter succeeded", name)
+		}
+	}
+}
@@ -3,13 +3,19 @@ package sstable
 import (
 	"encoding/binary"
 	"fmt"
+	"math"
 	"os"
+	"project/blockmanager"
 )
 
 // Index predstavlja sparse index za Data segment.
 type Index struct {
 	fileName     string
 	indexEntries []IndexEntry
+	section      section // deo fajla sa index-om (ceo fajl, osim kod tabele u jednom fajlu)
+	// legacyBlockSize != 0 znaci stari format u kom je offset broj bloka (4 bajta);
+	// pri citanju se pretvara u bajt offset pomocu velicine bloka
+	legacyBlockSize uint64
 }
 
 // NewIndex kreira novi Index objekat.
@@ -17,6 +23,7 @@ func NewIndex(fileName string, entries []IndexEntry) *Index {
 	return &Index{
 		fileName:     fileName,
 		indexEntries: entries,
+		section:      wholeFile,
 	}
 }
 
@@ -40,6 +47,11 @@ func (idx *Index) SetIndexEntries(entries []IndexEntry) {
 	idx.indexEntries = entries
 }
 
+// entrySize vraca velicinu zapisanog index entry-ja: velicina kljuca, kljuc i offset
+func entrySize(entry IndexEntry) int64 {
+	return 8 + int64(len(entry.Key)) + 8
+}
+
 // WriteToFile snima index entries u fajl.
 func (idx *Index) WriteToFile() error {
 	if idx.fileName == "" {
@@ -69,9 +81,9 @@ func (idx *Index) WriteToFile() error {
 			return err
 		}
 
-		// 3) offset (blok broj)
-		offBytes := make([]byte, 4)
-		binary.LittleEndian.PutUint32(offBytes, entry.Offset)
+		// 3) bajt offset bloka u data fajlu
+		offBytes := make([]byte, 8)
+		binary.LittleEndian.PutUint64(offBytes, entry.Offset)
 		if _, err := f.Write(offBytes); err != nil {
 			return err
 		}
@@ -82,78 +94,112 @@ func (idx *Index) WriteToFile() error {
 
 // ReadFromFile učitava sve IndexEntry iz fajla.
 func (idx *Index) ReadFromFile() ([]IndexEntry, error) {
-	f, err := os.Open(idx.fileName)
+	entries, err := idx.ReadFromOffset(0)
+	if err != nil {
+		return nil, err
+	}
+	idx.indexEntries = entries
+	return entries, nil
+}
+
+// ReadFromOffset učitava IndexEntry zapise od datog bajt offseta (npr. iz summary-ja) do kraja index-a.
+func (idx *Index) ReadFromOffset(offset int64) ([]IndexEntry, error) {
+	return idx.ReadRange(offset, -1)
+}
+
+// ReadRange učitava IndexEntry zapise izmedju bajt offseta start i end (end < 0 znaci do kraja index-a)
+// jednim citanjem sa pozicije, bez citanja ostatka fajla.
+func (idx *Index) ReadRange(start, end int64) ([]IndexEntry, error) {
+	f, section, err := openSection(idx.fileName, idx.section)
 	if err != nil {
 		return nil, fmt.Errorf("cannot open index file: %w", err)
 	}
 	defer f.Close()
 
-	entries := make([]IndexEntry, 0)
+	if end < 0 || end > section.Size() {
+		end = section.Size()
+	}
+	if start < 0 || start > end {
+		return nil, fmt.Errorf("invalid index range [%d, %d)", start, end)
+	}
+	buf := make([]byte, end-start)
+	if _, err := section.ReadAt(buf, start); err != nil {
+		return nil, fmt.Errorf("cannot read index file: %w", err)
+	}
+	return idx.parseEntries(buf)
+}
 
-	for {
+// parseEntries deserijalizuje niz zapisanih index entry-ja
+func (idx *Index) parseEntries(buf []byte) ([]IndexEntry, error) {
+	offsetSize := uint64(8)
+	if idx.legacyBlockSize != 0 {
+		offsetSize = 4
+	}
+
+	entries := make([]IndexEntry, 0)
+	for len(buf) > 0 {
 		// 1) key size
-		ksBytes := make([]byte, 8)
-		_, err := f.Read(ksBytes)
-		if err != nil {
-			break // EOF
+		if len(buf) < 8 {
+			return nil, fmt.Errorf("corrupted index file")
 		}
-		ks := binary.LittleEndian.Uint64(ksBytes)
-
-		// 2) key
-		key := make([]byte, ks)
-		if _, err := f.Read(key); err != nil {
-			return nil, err
+		ks := binary.LittleEndian.Uint64(buf)
+		buf = buf[8:]
+		if ks > uint64(len(buf)) || uint64(len(buf))-ks < offsetSize {
+			return nil, fmt.Errorf("corrupted index file")
 		}
 
-		// 3) offset (blok broj)
-		offBytes := make([]byte, 4)
-		if _, err := f.Read(offBytes); err != nil {
-			return nil, err
+		// 2) key
+		key := append([]byte(nil), buf[:ks]...)
+		buf = buf[ks:]
+
+		// 3) bajt offset bloka (u starom formatu broj bloka)
+		var offset uint64
+		if idx.legacyBlockSize != 0 {
+			blockNum := binary.LittleEndian.Uint32(buf)
+			offset = uint64(blockNum-1)*idx.legacyBlockSize + uint64(blockmanager.HEADER_SIZE)
+		} else {
+			offset = binary.LittleEndian.Uint64(buf)
 		}
-		offset := binary.LittleEndian.Uint32(offBytes)
+		buf = buf[offsetSize:]
 
 		entries = append(entries, IndexEntry{
 			Key:    key,
 			Offset: offset,
 		})
 	}
-	idx.indexEntries = entries
 	return entries, nil
 }
 
 // SearchIndex – binarna pretraga kroz indexEntries.
-// Vraća candidate offset (broj bloka) i bool found.
-func (idx *Index) SearchIndex(target []byte) (uint32, bool) {
-	if len(idx.indexEntries) == 0 {
-		return 0, false
+// Index je proredjen (prvi kljuc svakog bloka), pa je kandidat poslednji blok ciji je
+// prvi kljuc <= target. Ako vise blokova pocinje istim kljucem (podeljen rekord), vraca se prvi.
+// Vraća candidate offset (bajt offset bloka) i bool found; math.MaxUint64 ako je target manji od svih kljuceva.
+func (idx *Index) SearchIndex(target []byte) (uint64, bool) {
+	pos, found := findEntry(idx.indexEntries, target)
+	if pos == -1 {
+		return math.MaxUint64, false
 	}
+	return idx.indexEntries[pos].Offset, found
+}
 
-	lo, hi := 0, len(idx.indexEntries)-1
-	var candidate uint32 = 0
-	found := false
-
-	for lo <= hi {
+// findEntry vraca poziciju bloka u kom treba traziti target (vidi SearchIndex),
+// -1 ako je target manji od svih kljuceva, i da li je kljuc tacno pogodjen.
+func findEntry(entries []IndexEntry, target []byte) (int, bool) {
+	// lower bound: prvi entry ciji je kljuc >= target
+	lo, hi := 0, len(entries)
+	for lo < hi {
 		mid := (lo + hi) / 2
-		cmp := string(idx.indexEntries[mid].Key)
-
-		if cmp == string(target) {
-			// tačan pogodak
-			return idx.indexEntries[mid].Offset, true
-		} else if cmp > string(target) {
-			candidate = idx.indexEntries[mid].Offset
-			hi = mid - 1
-		} else {
+		if string(entries[mid].Key) < string(target) {
 			lo = mid + 1
+		} else {
+			hi = mid
 		}
 	}
 
-	// ako nismo pogodili, candidate će biti offset prvog ključa > target
-	// ako je target veći od svih, uzimamo poslednji offset
-	if !found {
-		if candidate == 0 {
-			candidate = idx.indexEntries[len(idx.indexEntries)-1].Offset
-		}
+	if lo < len(entries) && string(entries[lo].Key) == string(target) {
+		// tačan pogodak
+		return lo, true
 	}
-
-	return candidate, false
+	// lo == 0 znaci da je target manji od prvog kljuca u tabeli
+	return lo - 1, false
 }
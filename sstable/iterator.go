@@ -0,0 +1,103 @@
+package sstable
+
+import (
+	"fmt"
+	"os"
+	"project/blockmanager"
+)
+
+// Iterator redom vraca rekorde jedne tabele od minKey nadalje dok inRange vazi (ukljucujuci tombstone-ove).
+// Drzi otvoren data fajl i cita blok po blok, pa mora da se zatvori sa Close.
+type Iterator struct {
+	table     *SSTable
+	file      *os.File
+	entries   []IndexEntry // index entry-ji od pocetnog bloka do kraja tabele
+	block     []*blockmanager.Record
+	blockPos  int
+	assembler recordAssembler
+	minKey    string
+	inRange   func(key string) bool
+	done      bool
+}
+
+// MayContainRange proverava po opsegu iz summary hedera da li tabela moze imati neki kljuc >= minKey za koji vazi inRange
+func (t *SSTable) MayContainRange(minKey string, inRange func(key string) bool) bool {
+	return t.GetMaxKey() >= minKey && (t.GetMinKey() <= minKey || inRange(t.GetMinKey()))
+}
+
+// NewIterator pozicionira iterator na prvi kljuc >= minKey. Pocetni blok se nalazi preko
+// summary-ja i index-a, tako da se isti iterator koristi i za opseg i za prefiks.
+func (t *SSTable) NewIterator(minKey string, inRange func(key string) bool) (*Iterator, error) {
+	it := &Iterator{table: t, minKey: minKey, inRange: inRange}
+	if !t.MayContainRange(minKey, inRange) {
+		// tabela nema nijedan kljuc iz opsega
+		it.done = true
+		return it, nil
+	}
+
+	// ako je minKey manji od svih kljuceva, krece se od pocetka index fajla
+	offset, ok := t.summary.Find([]byte(minKey))
+	if !ok {
+		offset = 0
+	}
+	entries, err := t.index.ReadFromOffset(offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index: %v", err)
+	}
+	pos, _ := findEntry(entries, []byte(minKey))
+	if pos == -1 {
+		pos = 0
+	}
+	it.entries = entries[pos:]
+
+	it.file, err = os.Open(t.data.GetFileName())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open data file: %v", err)
+	}
+	return it, nil
+}
+
+// Next vraca sledeci rekord iz opsega; false kada ih vise nema ili citanje nije uspelo (vidi Err)
+func (it *Iterator) Next() (*blockmanager.Record, bool, error) {
+	for !it.done {
+		if it.blockPos >= len(it.block) {
+			if len(it.entries) == 0 {
+				it.done = true
+				break
+			}
+			records, err := it.table.data.readBlock(it.file, it.entries[0].Offset)
+			if err != nil {
+				it.done = true
+				return nil, false, fmt.Errorf("failed to read data block at offset %d: %v", it.entries[0].Offset, err)
+			}
+			it.entries = it.entries[1:]
+			it.block = records
+			it.blockPos = 0
+			continue
+		}
+
+		rec := it.block[it.blockPos]
+		it.blockPos++
+		whole := it.assembler.add(rec)
+		if whole == nil || whole.GetKey() < it.minKey {
+			continue
+		}
+		if !it.inRange(whole.GetKey()) {
+			it.done = true
+			break
+		}
+		return whole, true, nil
+	}
+	return nil, false, nil
+}
+
+// Close zatvara data fajl; iterator posle toga ne vraca vise rekorde
+func (it *Iterator) Close() error {
+	it.done = true
+	if it.file == nil {
+		return nil
+	}
+	err := it.file.Close()
+	it.file = nil
+	return err
+}
@@ -0,0 +1,82 @@
+package sstable
+
+import (
+	"container/heap"
+	"fmt"
+	"project/blockmanager"
+)
+
+// mergeSource je jedan ulaz k-way merge-a: sortirani rekordi jedne tabele
+type mergeSource struct {
+	records []*blockmanager.Record
+	pos     int
+	age     int // redni broj tabele, veci broj znaci novija tabela
+}
+
+func (s *mergeSource) current() *blockmanager.Record {
+	return s.records[s.pos]
+}
+
+// mergeHeap vraca najmanji kljuc, a za isti kljuc verziju iz najnovije tabele. Timestamp se ne gleda:
+// u sekundama je i moze da ide unazad sa satom, a redosled tabela uvek prati redosled upisa (kao kod GET-a).
+type mergeHeap []*mergeSource
+
+func (h mergeHeap) Len() int { return len(h) }
+func (h mergeHeap) Less(i, j int) bool {
+	a, b := h[i].current(), h[j].current()
+	if a.GetKey() != b.GetKey() {
+		return a.GetKey() < b.GetKey()
+	}
+	return h[i].age > h[j].age
+}
+func (h mergeHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *mergeHeap) Push(x any)   { *h = append(*h, x.(*mergeSource)) }
+func (h *mergeHeap) Pop() any {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
+
+// MergeTables spaja tabele (poredjane od najstarije ka najnovijoj) k-way merge-om nad
+// ReadAllDataBlocks i za svaki kljuc zadrzava samo verziju iz najnovije tabele.
+// Ako je dropTombstones true (nema starijih tabela ispod ulaza), obrisani kljucevi se izbacuju.
+func MergeTables(tables []*SSTable, dropTombstones bool) ([]*blockmanager.Record, error) {
+	h := make(mergeHeap, 0, len(tables))
+	for i, t := range tables {
+		records, err := t.data.ReadAllRecords()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read sstable %d: %v", t.id, err)
+		}
+		if len(records) > 0 {
+			h = append(h, &mergeSource{records: records, age: i})
+		}
+	}
+	heap.Init(&h)
+
+	merged := make([]*blockmanager.Record, 0)
+	lastKey := ""
+	first := true
+	for h.Len() > 0 {
+		src := h[0]
+		rec := src.current()
+
+		if first || rec.GetKey() != lastKey {
+			// prva (najnovija) verzija ovog kljuca
+			if !(dropTombstones && rec.GetTombstone() == 1) {
+				merged = append(merged, rec)
+			}
+			lastKey = rec.GetKey()
+			first = false
+		}
+
+		src.pos++
+		if src.pos < len(src.records) {
+			heap.Fix(&h, 0)
+		} else {
+			heap.Pop(&h)
+		}
+	}
+	return merged, nil
+}
@@ -0,0 +1,70 @@
+package sstable
+
+import (
+	"fmt"
+	"path/filepath"
+	"project/blockmanager"
+	"testing"
+)
+
+// stampedRecord pravi rekord sa zadatim timestamp-om, kao da je upisan dok je sat bio pomeren
+func stampedRecord(key, value string, tombstone uint8, timeStamp uint64) *blockmanager.Record {
+	record := blockmanager.SetRec(0, 0, tombstone, uint64(len(key)), uint64(len(value)), key, []byte(value))
+	record.SetTimeStamp(timeStamp)
+	record.SetCRCData(blockmanager.CRC32(blockmanager.Serialize(record)[blockmanager.CRC_SIZE:]))
+	return record
+}
+
+func writeTestTable(t *testing.T, id int, records ...*blockmanager.Record) *SSTable {
+	t.Helper()
+	files := TableFiles{Single: filepath.Join(t.TempDir(), fmt.Sprintf("table-%d.db", id))}
+	table, err := CreateSSTable(id, files, records, 4096, 2)
+	if err != nil {
+		t.Fatalf("CreateSSTable: %v", err)
+	}
+	return table
+}
+
+func TestMergeTablesKeepsNewestTable(t *testing.T) {
+	// novija tabela ima manje timestamp-ove, ali njene verzije su upisane kasnije
+	older := writeTestTable(t, 1,
+		stampedRecord("a", "old", 0, 200),
+		stampedRecord("b", "old", 0, 200),
+		stampedRecord("c", "old", 0, 200),
+	)
+	middle := writeTestTable(t, 2,
+		stampedRecord("a", "middle", 0, 300),
+		stampedRecord("e", "middle", 1, 300),
+	)
+	newer := writeTestTable(t, 3,
+		stampedRecord("a", "new", 0, 100),
+		stampedRecord("b", "", 1, 100),
+		stampedRecord("d", "new", 0, 100),
+	)
+	tables := []*SSTable{older, middle, newer}
+
+	cases := []struct {
+		dropTombstones bool
+		want           []string
+	}{
+		{false, []string{"a=new", "b deleted", "c=old", "d=new", "e deleted"}},
+		{true, []string{"a=new", "c=old", "d=new"}},
+	}
+	for _, c := range cases {
+		records, err := MergeTables(tables, c.dropTombstones)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := make([]string, 0, len(records))
+		for _, record := range records {
+			if record.GetTombstone() == 1 {
+				got = append(got, record.GetKey()+" deleted")
+			} else {
+				got = append(got, record.GetKey()+"="+string(record.GetValue()))
+			}
+		}
+		if fmt.Sprint(got) != fmt.Sprint(c.want) {
+			t.Errorf("MergeTables(dropTombstones=%v) = %v, want %v", c.dropTombstones, got, c.want)
+		}
+	}
+}
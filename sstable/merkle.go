@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"crypto/sha256"
 	"encoding/binary"
+	"io"
 	"os"
 	"project/blockmanager"
 )
@@ -142,7 +143,11 @@ func (mt *MerkleTree) Deserialize(filename string) {
 		panic(err)
 	}
 	defer file.Close()
+	mt.DeserializeFrom(file)
+}
 
+// DeserializeFrom cita stablo zapisano sa Serialize iz datog citaca (npr. dela fajla tabele)
+func (mt *MerkleTree) DeserializeFrom(file io.Reader) {
 	var readNode func() *TreeNode
 	readNode = func() *TreeNode {
 		flag := make([]byte, 1)
@@ -0,0 +1,160 @@
+package sstable
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Tabela u jednom fajlu: data blokovi (sa hederom, od pocetka fajla), pa index, summary,
+// bloom filter i Merkle metadata, a na kraju footer sa offsetom i velicinom svakog dela
+// (uint64, little endian, istim redom) i magicnim brojem.
+const (
+	SINGLE_FILE_MAGIC  uint64 = 0x4c474e4953545353 // "SSTSINGL"
+	NUM_SECTIONS              = 5
+	SINGLE_FOOTER_SIZE        = NUM_SECTIONS*16 + 8
+)
+
+// Redosled delova u fajlu i u footer-u
+const (
+	sectionData = iota
+	sectionIndex
+	sectionSummary
+	sectionFilter
+	sectionMetadata
+)
+
+// section je deo fajla u kom se nalazi jedan deo tabele
+type section struct {
+	offset int64
+	size   int64 // -1 znaci do kraja fajla
+}
+
+// wholeFile je deo koji zauzima ceo fajl, kao kod tabele u vise fajlova
+var wholeFile = section{offset: 0, size: -1}
+
+// openSection otvara fajl i vraca citac ogranicen na dati deo; pozivalac zatvara fajl
+func openSection(fileName string, sec section) (*os.File, *io.SectionReader, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, nil, err
+	}
+	size := sec.size
+	if size < 0 {
+		info, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return nil, nil, err
+		}
+		size = info.Size() - sec.offset
+	}
+	return f, io.NewSectionReader(f, sec.offset, size), nil
+}
+
+// readSection cita ceo deo fajla
+func readSection(fileName string, sec section) ([]byte, error) {
+	f, r, err := openSection(fileName, sec)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(r)
+}
+
+// singleFileParts vraca fajlove u koje se delovi tabele pisu pre spajanja: data se pise
+// odmah u konacni fajl (uvek je prvi deo), a ostali delovi u privremene fajlove pored njega
+func singleFileParts(files TableFiles) TableFiles {
+	return TableFiles{
+		Data:     files.Single,
+		Index:    files.Single + ".index.tmp",
+		Summary:  files.Single + ".summary.tmp",
+		Filter:   files.Single + ".filter.tmp",
+		Metadata: files.Single + ".metadata.tmp",
+	}
+}
+
+// joinSingleFile dopisuje index, summary, filter i metadata na data fajl, upisuje footer
+// i brise privremene fajlove. Vraca polozaj svakog dela u spojenom fajlu.
+func joinSingleFile(parts TableFiles) ([NUM_SECTIONS]section, error) {
+	var sections [NUM_SECTIONS]section
+
+	f, err := os.OpenFile(parts.Data, os.O_RDWR, 0644)
+	if err != nil {
+		return sections, fmt.Errorf("cannot open table file: %w", err)
+	}
+	defer f.Close()
+
+	end, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return sections, fmt.Errorf("cannot seek table file: %w", err)
+	}
+	sections[sectionData] = section{offset: 0, size: end}
+
+	paths := []string{parts.Index, parts.Summary, parts.Filter, parts.Metadata}
+	for i, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return sections, fmt.Errorf("cannot read table part: %w", err)
+		}
+		if _, err := f.Write(data); err != nil {
+			return sections, fmt.Errorf("cannot write table file: %w", err)
+		}
+		sections[sectionIndex+i] = section{offset: end, size: int64(len(data))}
+		end += int64(len(data))
+	}
+
+	footer := make([]byte, 0, SINGLE_FOOTER_SIZE)
+	for _, sec := range sections {
+		footer = binary.LittleEndian.AppendUint64(footer, uint64(sec.offset))
+		footer = binary.LittleEndian.AppendUint64(footer, uint64(sec.size))
+	}
+	footer = binary.LittleEndian.AppendUint64(footer, SINGLE_FILE_MAGIC)
+	if _, err := f.Write(footer); err != nil {
+		return sections, fmt.Errorf("cannot write table footer: %w", err)
+	}
+
+	for _, path := range paths {
+		if err := os.Remove(path); err != nil {
+			return sections, fmt.Errorf("cannot remove table part: %w", err)
+		}
+	}
+	return sections, nil
+}
+
+// readFooter cita polozaje delova iz footer-a tabele u jednom fajlu
+func readFooter(fileName string) ([NUM_SECTIONS]section, error) {
+	var sections [NUM_SECTIONS]section
+
+	f, err := os.Open(fileName)
+	if err != nil {
+		return sections, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return sections, err
+	}
+	footerOffset := info.Size() - SINGLE_FOOTER_SIZE
+	if footerOffset < 0 {
+		return sections, fmt.Errorf("table file %s is too short", fileName)
+	}
+	footer := make([]byte, SINGLE_FOOTER_SIZE)
+	if _, err := f.ReadAt(footer, footerOffset); err != nil {
+		return sections, fmt.Errorf("cannot read footer of %s: %w", fileName, err)
+	}
+	if binary.LittleEndian.Uint64(footer[NUM_SECTIONS*16:]) != SINGLE_FILE_MAGIC {
+		return sections, fmt.Errorf("table file %s has no footer", fileName)
+	}
+
+	for i := range sections {
+		offset := int64(binary.LittleEndian.Uint64(footer[16*i:]))
+		size := int64(binary.LittleEndian.Uint64(footer[16*i+8:]))
+		if offset < 0 || size < 0 || offset+size > footerOffset {
+			return sections, fmt.Errorf("table file %s has corrupted footer", fileName)
+		}
+		sections[i] = section{offset: offset, size: size}
+	}
+	return sections, nil
+}
@@ -0,0 +1,329 @@
+package sstable
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"project/blockmanager"
+)
+
+// TableFiles sadrzi putanje svih fajlova jedne generacije SSTable-a.
+// Ako je postavljen Single, cela tabela je u tom jednom fajlu (vidi single_file.go) i ostala polja se ne koriste.
+type TableFiles struct {
+	Data     string
+	Index    string
+	Summary  string
+	Filter   string
+	Metadata string
+	Single   string
+}
+
+// IsSingle proverava da li je tabela zapisana u jednom fajlu
+func (f TableFiles) IsSingle() bool {
+	return f.Single != ""
+}
+
+// Paths vraca putanje svih fajlova tabele
+func (f TableFiles) Paths() []string {
+	if f.IsSingle() {
+		return []string{f.Single}
+	}
+	return []string{f.Data, f.Index, f.Summary, f.Filter, f.Metadata}
+}
+
+// SSTable objedinjuje Data, Index, Summary, BloomFilter i Merkle stablo jedne generacije
+type SSTable struct {
+	id      int
+	files   TableFiles
+	data    *Data
+	index   *Index
+	summary *Summary // u hederu ima prvi i poslednji kljuc tabele
+	filter  *BloomFilter
+	mtree   *MerkleTree
+}
+
+// Getteri
+func (t *SSTable) GetID() int                 { return t.id }
+func (t *SSTable) GetFiles() TableFiles       { return t.files }
+func (t *SSTable) GetData() *Data             { return t.data }
+func (t *SSTable) GetIndex() *Index           { return t.index }
+func (t *SSTable) GetSummary() *Summary       { return t.summary }
+func (t *SSTable) GetFilter() *BloomFilter    { return t.filter }
+func (t *SSTable) GetMerkleTree() *MerkleTree { return t.mtree }
+func (t *SSTable) GetMinKey() string          { return string(t.summary.GetFirstKey()) }
+func (t *SSTable) GetMaxKey() string          { return string(t.summary.GetLastKey()) }
+
+// Overlaps proverava da li se opseg kljuceva tabele preklapa sa [minKey, maxKey]
+func (t *SSTable) Overlaps(minKey, maxKey string) bool {
+	return t.summary.Overlaps([]byte(minKey), []byte(maxKey))
+}
+
+// CreateSSTable upisuje sortirane rekorde u novu generaciju SSTable-a:
+// data, index, summary, bloom filter i Merkle metadata fajl, ili sve to u jedan fajl ako je files.Single postavljen.
+func CreateSSTable(id int, files TableFiles, records []*blockmanager.Record, blockSize uint64, summaryStep int) (*SSTable, error) {
+	if len(records) == 0 {
+		return nil, fmt.Errorf("no records to write to sstable %d", id)
+	}
+
+	// tabela u jednom fajlu se pise deo po deo, pa se delovi spajaju na kraju
+	parts := files
+	if files.IsSingle() {
+		parts = singleFileParts(files)
+	}
+
+	data := NewData(parts.Data, blockSize, blockSize*5)
+	indexEntries, err := data.WriteDataFile(records)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write data file: %v", err)
+	}
+
+	index := NewIndex(parts.Index, indexEntries)
+	if err := index.WriteToFile(); err != nil {
+		return nil, fmt.Errorf("failed to write index: %v", err)
+	}
+
+	summary, err := BuildSummaryFromIndex(index, parts.Summary, summaryStep, []byte(records[len(records)-1].GetKey()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build summary: %v", err)
+	}
+	if err := summary.WriteToFile(); err != nil {
+		return nil, fmt.Errorf("failed to write summary: %v", err)
+	}
+
+	// bloom filter sadrzi samo kljuceve ove tabele
+	filter := NewBloomFilter(len(records), 0.01)
+	for _, rec := range records {
+		filter.Add([]byte(rec.GetKey()))
+	}
+	if err := os.WriteFile(parts.Filter, filter.WriteBloomFilterFile(), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write bloom filter: %v", err)
+	}
+
+	// svaki index entry odgovara jednom data bloku, blokovi krecu od 1
+	mtree := CreateMerkleTree(data.GetDataBlocks(uint64(len(indexEntries))+1, parts.Data))
+	mtree.Serialize(parts.Metadata)
+
+	table := &SSTable{
+		id:      id,
+		files:   files,
+		data:    data,
+		index:   index,
+		summary: summary,
+		filter:  filter,
+		mtree:   mtree,
+	}
+	if files.IsSingle() {
+		sections, err := joinSingleFile(parts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to join table file: %v", err)
+		}
+		table.setSections(files.Single, sections)
+	}
+	if err := table.sync(); err != nil {
+		return nil, err
+	}
+	return table, nil
+}
+
+// setSections preusmerava data, index i summary na delove jednog fajla tabele
+func (t *SSTable) setSections(fileName string, sections [NUM_SECTIONS]section) {
+	t.data.SetFileName(fileName)
+	t.data.section = sections[sectionData]
+	t.index.SetFileName(fileName)
+	t.index.section = sections[sectionIndex]
+	t.summary.SetFileName(fileName)
+}
+
+// Get trazi kljuc u tabeli: opseg iz summary hedera -> bloom filter -> summary -> index -> data blok.
+// Vraca nil ako kljuc nije u tabeli; vraceni rekord moze biti i tombstone.
+func (t *SSTable) Get(key string) (*blockmanager.Record, error) {
+	if !t.summary.InRange([]byte(key)) {
+		return nil, nil
+	}
+	if !t.filter.Contains([]byte(key)) {
+		return nil, nil
+	}
+	start, end, ok := t.summary.FindRange([]byte(key))
+	if !ok {
+		return nil, nil
+	}
+
+	// iz index-a se jednim citanjem uzima samo deo na koji pokazuje summary, u lokalni niz,
+	// da bi istovremeni GET-ovi nad istom tabelom mogli da rade bez zakljucavanja
+	entries, err := t.index.ReadRange(start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index: %v", err)
+	}
+	pos, _ := findEntry(entries, []byte(key))
+	if pos == -1 {
+		return nil, nil
+	}
+	blockOffset := entries[pos].Offset
+
+	record, _, err := t.data.FindInBlock(blockOffset, []byte(key))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read data block at offset %d: %v", blockOffset, err)
+	}
+	return record, nil
+}
+
+// OpenSSTable ponovo otvara generaciju SSTable-a koja vec postoji na disku, u vise fajlova ili u jednom.
+// Velicina bloka se cita iz hedera data fajla, jer je tabela mozda pisana sa drugacijim configom.
+func OpenSSTable(id int, files TableFiles, poolSize uint64) (*SSTable, error) {
+	// za svaki deo tabele: fajl i deo fajla u kom se nalazi
+	parts := files
+	sections := [NUM_SECTIONS]section{wholeFile, wholeFile, wholeFile, wholeFile, wholeFile}
+	if files.IsSingle() {
+		parts = TableFiles{Data: files.Single, Index: files.Single, Summary: files.Single, Filter: files.Single, Metadata: files.Single}
+		var err error
+		if sections, err = readFooter(files.Single); err != nil {
+			return nil, err
+		}
+	}
+
+	blockSize, err := readBlockSize(parts.Data)
+	if err != nil {
+		return nil, err
+	}
+	data := NewData(parts.Data, blockSize, poolSize)
+	data.section = sections[sectionData]
+
+	index := NewIndex(parts.Index, nil)
+	index.section = sections[sectionIndex]
+
+	summary, err := readSummary(parts.Summary, sections[sectionSummary])
+	if err != nil {
+		return nil, fmt.Errorf("failed to read summary: %v", err)
+	}
+	if len(summary.entries) == 0 {
+		return nil, fmt.Errorf("summary file %s is empty", parts.Summary)
+	}
+	if !summary.byteOffsets {
+		index.legacyBlockSize = blockSize
+	}
+	if !summary.hasBounds() {
+		// summary bez hedera (stariji format): opseg se racuna iz index-a i poslednjeg data bloka
+		if err := findBounds(data, index, summary); err != nil {
+			return nil, err
+		}
+	}
+
+	filterData, err := readSection(parts.Filter, sections[sectionFilter])
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bloom filter: %v", err)
+	}
+	filter, err := ReadBloomFilter(filterData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bloom filter: %v", err)
+	}
+
+	metaFile, metaSection, err := openSection(parts.Metadata, sections[sectionMetadata])
+	if err != nil {
+		return nil, fmt.Errorf("failed to open metadata: %v", err)
+	}
+	defer metaFile.Close()
+	mtree := &MerkleTree{}
+	mtree.DeserializeFrom(metaSection)
+	if mtree.root == nil {
+		return nil, fmt.Errorf("metadata file %s is empty", parts.Metadata)
+	}
+
+	return &SSTable{
+		id:      id,
+		files:   files,
+		data:    data,
+		index:   index,
+		summary: summary,
+		filter:  filter,
+		mtree:   mtree,
+	}, nil
+}
+
+// findBounds postavlja prvi i poslednji kljuc summary-ja tabele ciji summary nema heder:
+// najmanji kljuc je prvi u index-u, a najveci poslednji rekord u poslednjem data bloku
+func findBounds(data *Data, index *Index, summary *Summary) error {
+	entries, err := index.ReadFromFile()
+	if err != nil {
+		return fmt.Errorf("failed to read index: %v", err)
+	}
+	if len(entries) == 0 {
+		return fmt.Errorf("index file %s is empty", index.GetFileName())
+	}
+	lastBlock, err := data.ReadDataFile(entries[len(entries)-1].Offset)
+	if err != nil {
+		return fmt.Errorf("failed to read last data block: %v", err)
+	}
+	if len(lastBlock) == 0 {
+		return fmt.Errorf("last data block of %s is empty", data.GetFileName())
+	}
+	summary.SetBounds(entries[0].Key, []byte(lastBlock[len(lastBlock)-1].GetKey()))
+	return nil
+}
+
+// readBlockSize cita velicinu bloka iz hedera data fajla
+func readBlockSize(fileName string) (uint64, error) {
+	header := blockmanager.ReadHeader(fileName)
+	if header == nil {
+		return 0, fmt.Errorf("failed to read header of %s", fileName)
+	}
+	for _, record := range header.GetRecords() {
+		if record.GetKey() == "block size" && len(record.GetValue()) == 8 {
+			return binary.LittleEndian.Uint64(record.GetValue()), nil
+		}
+	}
+	return 0, fmt.Errorf("data file %s has no block size in header", fileName)
+}
+
+// GetSize vraca velicinu data fajla (ili data dela jednog fajla) u bajtovima
+func (t *SSTable) GetSize() (int64, error) {
+	if t.data.section.size >= 0 {
+		return t.data.section.size, nil
+	}
+	info, err := os.Stat(t.files.Data)
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// Remove brise sve fajlove tabele sa diska
+func (t *SSTable) Remove() error {
+	for _, path := range t.files.Paths() {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove %s: %v", path, err)
+		}
+	}
+	return nil
+}
+
+// sync radi fsync svih fajlova tabele i njihovih direktorijuma; tabela mora biti na disku
+// pre nego sto je manifest navede i pre nego sto se obrisu segmenti WAL-a sa njenim rekordima
+func (t *SSTable) sync() error {
+	dirs := make(map[string]bool)
+	for _, path := range t.files.Paths() {
+		if err := syncPath(path); err != nil {
+			return err
+		}
+		dirs[filepath.Dir(path)] = true
+	}
+	for dir := range dirs {
+		if err := syncPath(dir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncPath radi fsync fajla ili direktorijuma
+func syncPath(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open %s for sync: %v", path, err)
+	}
+	defer file.Close()
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync %s: %v", path, err)
+	}
+	return nil
+}
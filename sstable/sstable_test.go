@@ -0,0 +1,74 @@
+package sstable
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"project/blockmanager"
+	"testing"
+)
+
+// splitTestRecords pravi sortirane rekorde izmedju kojih su rekordi veci od bloka, ukljucujuci i
+// one cija je vrednost tacan umnozak mesta za vrednost u jednom delu
+func splitTestRecords(blockSize uint64) []*blockmanager.Record {
+	records := make([]*blockmanager.Record, 0)
+	for i := 0; i < 12; i++ {
+		key := fmt.Sprintf("key-%02d", i)
+		spaceLeft := int(blockSize) - blockmanager.RECORD_BASE_SIZE - len(key)
+		sizes := []int{5, 3 * spaceLeft, 2*spaceLeft + 7, spaceLeft + 1}
+		size := sizes[i%len(sizes)]
+		value := bytes.Repeat([]byte{byte('a' + i)}, size)
+		records = append(records, blockmanager.SetRec(0, uint64(i), 0, uint64(len(key)), uint64(size), key, value))
+	}
+	return records
+}
+
+func TestSplitRecordsAcrossBlocks(t *testing.T) {
+	const blockSize = 100
+	dir := t.TempDir()
+	layouts := map[string]TableFiles{
+		"files": {
+			Data:     filepath.Join(dir, "data"),
+			Index:    filepath.Join(dir, "index"),
+			Summary:  filepath.Join(dir, "summary"),
+			Filter:   filepath.Join(dir, "filter"),
+			Metadata: filepath.Join(dir, "metadata"),
+		},
+		"single": {Single: filepath.Join(dir, "single")},
+	}
+	for name, files := range layouts {
+		t.Run(name, func(t *testing.T) {
+			records := splitTestRecords(blockSize)
+			if _, err := CreateSSTable(1, files, records, blockSize, 2); err != nil {
+				t.Fatalf("CreateSSTable: %v", err)
+			}
+			table, err := OpenSSTable(1, files, blockSize*5)
+			if err != nil {
+				t.Fatalf("OpenSSTable: %v", err)
+			}
+
+			for _, want := range records {
+				got, err := table.Get(want.GetKey())
+				if err != nil {
+					t.Fatalf("Get %s: %v", want.GetKey(), err)
+				}
+				if got == nil || !bytes.Equal(got.GetValue(), want.GetValue()) {
+					t.Fatalf("Get %s returned %v, want value of %d bytes", want.GetKey(), got, len(want.GetValue()))
+				}
+			}
+
+			all, err := table.GetData().ReadAllRecords()
+			if err != nil {
+				t.Fatalf("ReadAllRecords: %v", err)
+			}
+			if len(all) != len(records) {
+				t.Fatalf("ReadAllRecords returned %d records, want %d", len(all), len(records))
+			}
+			for i, got := range all {
+				if got.GetKey() != records[i].GetKey() || !bytes.Equal(got.GetValue(), records[i].GetValue()) {
+					t.Fatalf("record %d is %s, want %s", i, got.GetKey(), records[i].GetKey())
+				}
+			}
+		})
+	}
+}
@@ -1,19 +1,35 @@
 package sstable
 
 import (
+	"bufio"
 	"encoding/binary"
 	"fmt"
+	"io"
 	"os"
 )
 
+// SUMMARY_MAGIC oznacava pocetak hedera summary fajla. Stari fajlovi bez hedera pocinju velicinom
+// kljuca, koja nikad nije ovolika, pa se svi formati mogu citati.
+// Uz SUMMARY_MAGIC index cuva bajt offsete blokova; uz SUMMARY_MAGIC_V1 i bez hedera cuva brojeve blokova.
+const (
+	SUMMARY_MAGIC    uint64 = 0x3252444859524d53 // "SMRYHDR2"
+	SUMMARY_MAGIC_V1 uint64 = 0x3152444859524d53 // "SMRYHDR1"
+)
+
 type SummaryEntry struct {
 	Key         []byte
 	IndexOffset int64
 }
 
+// Summary cuva svaki N-ti index entry, a u hederu prvi i poslednji kljuc tabele,
+// da bi se tabela preskocila bez citanja bloom filtera i index-a kad kljuc nije u njenom opsegu
 type Summary struct {
 	fileName string
+	firstKey []byte
+	lastKey  []byte
 	entries  []SummaryEntry
+	// byteOffsets je false za starije formate u kojima index cuva brojeve blokova
+	byteOffsets bool
 }
 
 // Getters/Setters
@@ -21,6 +37,24 @@ func (s *Summary) SetFileName(name string) { s.fileName = name }
 func (s *Summary) GetFileName() string     { return s.fileName }
 
 func (s *Summary) GetEntries() []SummaryEntry { return s.entries }
+func (s *Summary) GetFirstKey() []byte        { return s.firstKey }
+func (s *Summary) GetLastKey() []byte         { return s.lastKey }
+
+// SetBounds postavlja prvi i poslednji kljuc tabele
+func (s *Summary) SetBounds(firstKey, lastKey []byte) {
+	s.firstKey = firstKey
+	s.lastKey = lastKey
+}
+
+// InRange proverava da li je kljuc izmedju prvog i poslednjeg kljuca tabele
+func (s *Summary) InRange(key []byte) bool {
+	return string(s.firstKey) <= string(key) && string(key) <= string(s.lastKey)
+}
+
+// Overlaps proverava da li se opseg tabele preklapa sa [minKey, maxKey]
+func (s *Summary) Overlaps(minKey, maxKey []byte) bool {
+	return string(s.firstKey) <= string(maxKey) && string(minKey) <= string(s.lastKey)
+}
 
 // WriteToFile – serijalizuje summary u fajl
 func (s *Summary) WriteToFile() error {
@@ -48,7 +82,19 @@ func (s *Summary) WriteToFile() error {
 		return nil
 	}
 
-	// upiši samo entries
+	// heder: magic, prvi i poslednji kljuc
+	magicBytes := make([]byte, 8)
+	binary.LittleEndian.PutUint64(magicBytes, SUMMARY_MAGIC)
+	if _, err := f.Write(magicBytes); err != nil {
+		return err
+	}
+	if err := writeKey(s.firstKey); err != nil {
+		return err
+	}
+	if err := writeKey(s.lastKey); err != nil {
+		return err
+	}
+
 	for _, e := range s.entries {
 		if err := writeKey(e.Key); err != nil {
 			return err
@@ -65,133 +111,176 @@ func (s *Summary) WriteToFile() error {
 
 // ReadFromFile – deserijalizacija Summary fajla
 func ReadFromFile(fileName string) ([]SummaryEntry, error) {
-	f, err := os.Open(fileName)
+	summary, err := readSummary(fileName, wholeFile)
+	if err != nil {
+		return nil, err
+	}
+	return summary.entries, nil
+}
+
+// readSummary deserijalizuje summary iz datog dela fajla. Kod starog formata bez hedera
+// prvi i poslednji kljuc ostaju prazni (vidi hasBounds).
+func readSummary(fileName string, sec section) (*Summary, error) {
+	f, section, err := openSection(fileName, sec)
 	if err != nil {
 		return nil, fmt.Errorf("cannot open summary file: %w", err)
 	}
 	defer f.Close()
+	r := bufio.NewReader(section)
 
-	readKey := func() ([]byte, error) {
-		ksBytes := make([]byte, 8)
-		_, err := f.Read(ksBytes)
-		if err != nil {
-			return nil, err
-		}
-		ks := binary.LittleEndian.Uint64(ksBytes)
+	readKeyOfSize := func(ks uint64) ([]byte, error) {
 		key := make([]byte, ks)
-		if _, err := f.Read(key); err != nil {
+		if _, err := io.ReadFull(r, key); err != nil {
 			return nil, err
 		}
 		return key, nil
 	}
+	readUint64 := func() (uint64, error) {
+		b := make([]byte, 8)
+		if _, err := io.ReadFull(r, b); err != nil {
+			return 0, err
+		}
+		return binary.LittleEndian.Uint64(b), nil
+	}
+	readKey := func() ([]byte, error) {
+		ks, err := readUint64()
+		if err != nil {
+			return nil, err
+		}
+		return readKeyOfSize(ks)
+	}
 
-	entries := make([]SummaryEntry, 0)
+	summary := &Summary{fileName: fileName, entries: make([]SummaryEntry, 0)}
+	first := true
 	for {
-		key, err := readKey()
+		ks, err := readUint64()
 		if err != nil {
 			break // EOF
 		}
+		if first && (ks == SUMMARY_MAGIC || ks == SUMMARY_MAGIC_V1) {
+			summary.byteOffsets = ks == SUMMARY_MAGIC
+			if summary.firstKey, err = readKey(); err != nil {
+				return nil, fmt.Errorf("corrupted summary header: %w", err)
+			}
+			if summary.lastKey, err = readKey(); err != nil {
+				return nil, fmt.Errorf("corrupted summary header: %w", err)
+			}
+			first = false
+			continue
+		}
+		first = false
 
-		offBytes := make([]byte, 8)
-		if _, err := f.Read(offBytes); err != nil {
+		key, err := readKeyOfSize(ks)
+		if err != nil {
+			return nil, err
+		}
+		offset, err := readUint64()
+		if err != nil {
 			return nil, err
 		}
-		offset := int64(binary.LittleEndian.Uint64(offBytes))
 
-		entries = append(entries, SummaryEntry{
+		summary.entries = append(summary.entries, SummaryEntry{
 			Key:         key,
-			IndexOffset: offset,
+			IndexOffset: int64(offset),
 		})
 	}
 
-	return entries, nil
+	return summary, nil
 }
-func NewSummary(filename string) *Summary {
-	ent, _ := ReadFromFile(filename)
 
-	return &Summary{
-		fileName: filename,
-		entries:  ent,
-	}
+// hasBounds proverava da li summary ima prvi i poslednji kljuc (fajlovi bez hedera ih nemaju)
+func (s *Summary) hasBounds() bool {
+	return s.firstKey != nil && s.lastKey != nil
 }
 
-func BuildSummaryFromIndex(indexFile string, summaryFile string, N int) (*Summary, error) {
-	f, err := os.Open(indexFile)
+func NewSummary(filename string) *Summary {
+	summary, err := readSummary(filename, wholeFile)
 	if err != nil {
-		return nil, fmt.Errorf("cannot open index file: %w", err)
+		return &Summary{fileName: filename}
 	}
-	defer f.Close()
+	return summary
+}
 
-	summary := &Summary{fileName: summaryFile}
-	entries := make([]SummaryEntry, 0)
+// BuildSummaryFromIndex pravi summary od svakog N-tog entry-ja index-a. Offseti u index fajlu se racunaju
+// iz velicina zapisanih entry-ja, bez ponovnog citanja fajla. Prvi kljuc tabele je prvi kljuc u index-u,
+// a poslednji se prosledjuje jer index sadrzi samo prve kljuceve blokova.
+func BuildSummaryFromIndex(index *Index, summaryFile string, N int, lastKey []byte) (*Summary, error) {
+	indexEntries := index.GetIndexEntries()
+	if len(indexEntries) == 0 {
+		return nil, fmt.Errorf("index file %s is empty", index.GetFileName())
+	}
 
+	entries := make([]SummaryEntry, 0, len(indexEntries)/N+1)
 	var offset int64 = 0
-	var entryCount int = 0
-
-	for {
-		// 1) key size -- ovo make i 8 key je sigurno veci?
-		ksBytes := make([]byte, 8)
-		n, err := f.Read(ksBytes)
-		if err != nil || n == 0 {
-			break // EOF
-		}
-		if n < 8 {
-			return nil, fmt.Errorf("corrupted index file")
-		}
-		ks := binary.LittleEndian.Uint64(ksBytes)
-		offset += int64(n)
-
-		// 2) key
-		key := make([]byte, ks)
-		n, err = f.Read(key)
-		if err != nil || n == 0 {
-			return nil, fmt.Errorf("corrupted index file")
-		}
-		offset += int64(n)
-
-		// 3) offset (4 bajta)
-		offBytes := make([]byte, 4)
-		n, err = f.Read(offBytes)
-		if err != nil {
-			return nil, fmt.Errorf("corrupted index file")
-		}
-		offset += int64(n)
-
-		if entryCount%N == 0 {
+	for i, entry := range indexEntries {
+		if i%N == 0 {
 			entries = append(entries, SummaryEntry{
-				Key:         append([]byte(nil), key...),
-				IndexOffset: offset - (8 + int64(len(key)) + 4),
+				Key:         append([]byte(nil), entry.Key...),
+				IndexOffset: offset,
 			})
 		}
-
-		entryCount++
+		offset += entrySize(entry)
 	}
 
-	summary.entries = entries
-	return summary, nil
+	return &Summary{
+		fileName:    summaryFile,
+		firstKey:    entries[0].Key,
+		lastKey:     lastKey,
+		entries:     entries,
+		byteOffsets: true,
+	}, nil
 }
 
+// Find vraca offset u index fajlu od kog treba traziti target: offset poslednjeg summary
+// entry-ja ciji je kljuc < target. Podeljen rekord ima isti kljuc u vise uzastopnih index
+// entry-ja, a summary ne mora da sadrzi prvi od njih, pa pretraga krece od manjeg kljuca.
+// Ako je target jednak prvom kljucu, krece se od pocetka index-a.
+// Ako je target manji od svih kljuceva (ili je summary prazan) vraca (0, false) jer kljuc ne moze biti u tabeli.
 func (s *Summary) Find(target []byte) (int64, bool) {
 	if len(s.entries) == 0 {
 		return 0, false
 	}
 
-	lo, hi := 0, len(s.entries)-1
-	var candidate int64 = -1
-
-	for lo <= hi {
+	// prvi entry ciji je kljuc >= target
+	lo, hi := 0, len(s.entries)
+	for lo < hi {
 		mid := (lo + hi) / 2
-		if string(s.entries[mid].Key) >= string(target) {
-			candidate = s.entries[mid].IndexOffset
-			hi = mid - 1
-		} else {
+		if string(s.entries[mid].Key) < string(target) {
 			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+
+	if lo == 0 {
+		if string(s.entries[0].Key) == string(target) {
+			return s.entries[0].IndexOffset, true
 		}
+		return 0, false
 	}
+	return s.entries[lo-1].IndexOffset, true
+}
 
-	if candidate == -1 {
-		// target veći od svih → vrati poslednji offset
-		return s.entries[len(s.entries)-1].IndexOffset, true
+// FindRange vraca deo index fajla [start, end) u kom je entry bloka sa target-om: start je isti kao kod Find,
+// a end je offset prvog summary entry-ja ciji je kljuc > target (-1 znaci do kraja index-a).
+func (s *Summary) FindRange(target []byte) (int64, int64, bool) {
+	start, ok := s.Find(target)
+	if !ok {
+		return 0, 0, false
+	}
+
+	// prvi entry ciji je kljuc > target
+	lo, hi := 0, len(s.entries)
+	for lo < hi {
+		mid := (lo + hi) / 2
+		if string(s.entries[mid].Key) <= string(target) {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+	if lo == len(s.entries) {
+		return start, -1, true
 	}
-	return candidate, true
+	return start, s.entries[lo].IndexOffset, true
 }
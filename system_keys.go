@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// systemKeyPrefix je rezervisani prefiks kljuceva sa internim stanjem (npr. stanje rate limiter-a).
+// Sistemski kljucevi idu kroz isti WAL, memtable i SSTable put kao korisnicki, ali ih korisnicke komande ne vide.
+const systemKeyPrefix = "__system__/"
+
+// isSystemKey proverava da li kljuc pripada sistemu
+func isSystemKey(key string) bool {
+	return strings.HasPrefix(key, systemKeyPrefix)
+}
+
+// systemKey vraca pun kljuc za ime sistemske vrednosti
+func systemKey(name string) string {
+	return systemKeyPrefix + name
+}
+
+// checkUserKey odbija korisnicke komande nad sistemskim kljucevima
+func checkUserKey(key string) error {
+	if isSystemKey(key) {
+		return fmt.Errorf("key %q is reserved (prefix %q)", key, systemKeyPrefix)
+	}
+	return nil
+}
+
+// getSystem cita sistemsku vrednost; nil ako ne postoji ili je obrisana
+func (manager *Manager) getSystem(name string) ([]byte, error) {
+	record, _, err := manager.find(systemKey(name))
+	if err != nil || record == nil || record.GetTombstone() == 1 {
+		return nil, err
+	}
+	return record.GetValue(), nil
+}
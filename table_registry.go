@@ -0,0 +1,280 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"project/blockmanager"
+	"project/sstable"
+	"sort"
+)
+
+// manifestFile je fajl (u direktorijumu SSTable-ova) sa spiskom zivih generacija i nivoa na kom se nalaze.
+// Zamena manifesta (upis u .tmp pa rename) je trenutak u kom flush ili kompakcija postaju vidljivi.
+const manifestFile = "MANIFEST"
+
+// manifestEntry je jedna ziva tabela u manifestu
+type manifestEntry struct {
+	id    int
+	level int
+}
+
+// TableRegistry pamti sve generacije SSTable-a koje je Manager upisao, po nivoima.
+// Nivo 0 prima flush-eve memtable-a i tabele su poredjane od najstarije ka najnovijoj (opsezi se preklapaju).
+// Na nivoima >= 1 opsezi kljuceva se ne preklapaju i tabele su sortirane po najmanjem kljucu.
+// Size-tiered kompakcija koristi samo nivo 0.
+type TableRegistry struct {
+	levels       [][]*sstable.SSTable
+	manifestPath string
+}
+
+func NewTableRegistry(manifestPath string) *TableRegistry {
+	return &TableRegistry{
+		levels:       [][]*sstable.SSTable{make([]*sstable.SSTable, 0)},
+		manifestPath: manifestPath,
+	}
+}
+
+// Add dodaje novu (najnoviju) tabelu na nivo 0 i snima manifest.
+// Ako manifest ne moze da se snimi tabela se uklanja iz registra, da je ponovljen flush ne bi dodao dvaput.
+func (r *TableRegistry) Add(table *sstable.SSTable) error {
+	r.levels[0] = append(r.levels[0], table)
+	if err := r.saveManifest(); err != nil {
+		r.levels[0] = r.levels[0][:len(r.levels[0])-1]
+		return err
+	}
+	return nil
+}
+
+// Replace menja uzastopne ulazne tabele nivoa 0 jednom izlaznom tabelom (na mestu najnovijeg ulaza)
+// i snima manifest. Ako je output nil, ulazi se samo uklanjaju.
+// Fajlove ulaznih tabela brise pozivalac, tek posto je manifest snimljen.
+func (r *TableRegistry) Replace(inputs []*sstable.SSTable, output *sstable.SSTable) error {
+	remove := tableSet(inputs)
+	last := -1
+	for i, t := range r.levels[0] {
+		if remove[t.GetID()] {
+			last = i
+		}
+	}
+	if last == -1 {
+		return fmt.Errorf("compaction inputs are not registered")
+	}
+
+	tables := make([]*sstable.SSTable, 0, len(r.levels[0])-len(inputs)+1)
+	for i, t := range r.levels[0] {
+		if i == last && output != nil {
+			tables = append(tables, output)
+		} else if !remove[t.GetID()] {
+			tables = append(tables, t)
+		}
+	}
+	r.levels[0] = tables
+	return r.saveManifest()
+}
+
+// ReplaceLevel uklanja ulazne tabele sa svih nivoa, dodaje izlazne na dati nivo (>= 1)
+// i snima manifest. Fajlove ulaznih tabela brise pozivalac, tek posto je manifest snimljen.
+func (r *TableRegistry) ReplaceLevel(inputs []*sstable.SSTable, outputs []*sstable.SSTable, level int) error {
+	remove := tableSet(inputs)
+	for i := range r.levels {
+		kept := make([]*sstable.SSTable, 0, len(r.levels[i]))
+		for _, t := range r.levels[i] {
+			if !remove[t.GetID()] {
+				kept = append(kept, t)
+			}
+		}
+		r.levels[i] = kept
+	}
+	for _, t := range outputs {
+		r.addToLevel(t, level)
+	}
+	r.sortLevel(level)
+	return r.saveManifest()
+}
+
+// addToLevel dodaje tabelu na nivo bez snimanja manifesta, po potrebi pravi nove nivoe
+func (r *TableRegistry) addToLevel(table *sstable.SSTable, level int) {
+	for len(r.levels) <= level {
+		r.levels = append(r.levels, make([]*sstable.SSTable, 0))
+	}
+	r.levels[level] = append(r.levels[level], table)
+}
+
+// sortLevel sortira nivo >= 1 po najmanjem kljucu, da bi mogao binarno da se pretrazuje
+func (r *TableRegistry) sortLevel(level int) {
+	if level == 0 || level >= len(r.levels) {
+		return
+	}
+	tables := r.levels[level]
+	sort.Slice(tables, func(i, j int) bool {
+		return tables[i].GetMinKey() < tables[j].GetMinKey()
+	})
+}
+
+// GetTables vraca sve tabele: nivo 0 od najstarije ka najnovijoj, pa ostale nivoe
+func (r *TableRegistry) GetTables() []*sstable.SSTable {
+	tables := make([]*sstable.SSTable, 0)
+	for _, level := range r.levels {
+		tables = append(tables, level...)
+	}
+	return tables
+}
+
+// NewestFirst vraca sve tabele od najnovije ka najstarijoj: nivo 0 obrnutim redom, pa dublji nivoi
+func (r *TableRegistry) NewestFirst() []*sstable.SSTable {
+	tables := make([]*sstable.SSTable, 0, r.Len())
+	for i := len(r.levels[0]) - 1; i >= 0; i-- {
+		tables = append(tables, r.levels[0][i])
+	}
+	for _, level := range r.levels[1:] {
+		tables = append(tables, level...)
+	}
+	return tables
+}
+
+// GetLevel vraca tabele na datom nivou
+func (r *TableRegistry) GetLevel(level int) []*sstable.SSTable {
+	if level >= len(r.levels) {
+		return nil
+	}
+	return r.levels[level]
+}
+
+// NumLevels vraca broj nivoa koji trenutno postoje
+func (r *TableRegistry) NumLevels() int {
+	return len(r.levels)
+}
+
+// Len vraca broj registrovanih tabela
+func (r *TableRegistry) Len() int {
+	total := 0
+	for _, level := range r.levels {
+		total += len(level)
+	}
+	return total
+}
+
+// Get trazi kljuc od najnovije ka najstarijoj tabeli i staje na prvom pogotku.
+// Na nivou 0 proverava sve tabele, a na dubljim nivoima binarnom pretragom
+// po opsezima nalazi jedinu tabelu koja moze da sadrzi kljuc.
+// Tombstone se takodje vraca kao pogodak da starije verzije ne bi "ozivele".
+func (r *TableRegistry) Get(key string) (*blockmanager.Record, error) {
+	for i := len(r.levels[0]) - 1; i >= 0; i-- {
+		record, err := r.levels[0][i].Get(key)
+		if err != nil {
+			return nil, err
+		}
+		if record != nil {
+			return record, nil
+		}
+	}
+
+	for level := 1; level < len(r.levels); level++ {
+		tables := r.levels[level]
+		// prva tabela ciji je najveci kljuc >= key
+		i := sort.Search(len(tables), func(i int) bool {
+			return tables[i].GetMaxKey() >= key
+		})
+		if i == len(tables) || tables[i].GetMinKey() > key {
+			continue
+		}
+		record, err := tables[i].Get(key)
+		if err != nil {
+			return nil, err
+		}
+		if record != nil {
+			return record, nil
+		}
+	}
+	return nil, nil
+}
+
+// tableSet vraca skup ID-jeva datih tabela
+func tableSet(tables []*sstable.SSTable) map[int]bool {
+	set := make(map[int]bool)
+	for _, t := range tables {
+		set[t.GetID()] = true
+	}
+	return set
+}
+
+// saveManifest upisuje zive tabele: broj tabela, pa za svaku ID i nivo (uint64, little endian).
+// Tabele nivoa 0 se upisuju od najstarije ka najnovijoj jer redosled odredjuje koja verzija pobedjuje.
+func (r *TableRegistry) saveManifest() error {
+	data := make([]byte, 8, 8+16*r.Len())
+	binary.LittleEndian.PutUint64(data, uint64(r.Len()))
+	for level, tables := range r.levels {
+		for _, t := range tables {
+			data = binary.LittleEndian.AppendUint64(data, uint64(t.GetID()))
+			data = binary.LittleEndian.AppendUint64(data, uint64(level))
+		}
+	}
+
+	// tmp se fsync-uje pre rename-a, a direktorijum posle, pa posle pada ostaje ili stari ili ceo novi manifest
+	tmp := r.manifestPath + ".tmp"
+	if err := writeFileSync(tmp, data); err != nil {
+		return fmt.Errorf("failed to write manifest: %v", err)
+	}
+	if err := os.Rename(tmp, r.manifestPath); err != nil {
+		return fmt.Errorf("failed to replace manifest: %v", err)
+	}
+	if err := syncDir(filepath.Dir(r.manifestPath)); err != nil {
+		return fmt.Errorf("failed to replace manifest: %v", err)
+	}
+	return nil
+}
+
+// writeFileSync upisuje fajl i radi fsync pre zatvaranja
+func writeFileSync(path string, data []byte) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// syncDir radi fsync direktorijuma, da bi rename u njemu bio trajan
+func syncDir(path string) error {
+	dir, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+	return dir.Sync()
+}
+
+// loadManifest cita zive tabele; vraca false ako manifest jos ne postoji
+func loadManifest(manifestPath string) ([]manifestEntry, bool, error) {
+	data, err := os.ReadFile(manifestPath)
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read manifest: %v", err)
+	}
+	if len(data) < 8 {
+		return nil, false, fmt.Errorf("manifest is corrupted")
+	}
+	count := binary.LittleEndian.Uint64(data)
+	if uint64(len(data)) != 8+16*count {
+		return nil, false, fmt.Errorf("manifest is corrupted")
+	}
+	entries := make([]manifestEntry, 0, count)
+	for i := uint64(0); i < count; i++ {
+		entries = append(entries, manifestEntry{
+			id:    int(binary.LittleEndian.Uint64(data[8+16*i:])),
+			level: int(binary.LittleEndian.Uint64(data[16+16*i:])),
+		})
+	}
+	return entries, true, nil
+}
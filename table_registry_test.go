@@ -0,0 +1,27 @@
+package main
+
+import (
+	"path/filepath"
+	"project/blockmanager"
+	"project/sstable"
+	"testing"
+)
+
+func TestTableRegistryAddUndoesFailedManifest(t *testing.T) {
+	dir := t.TempDir()
+	files := sstable.TableFiles{Single: filepath.Join(dir, "table.db")}
+	record := blockmanager.SetRec(0, 1, 0, 1, 1, "k", []byte("v"))
+	table, err := sstable.CreateSSTable(1, files, []*blockmanager.Record{record}, 4096, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// manifest je u direktorijumu koji ne postoji, pa snimanje ne uspeva
+	registry := NewTableRegistry(filepath.Join(dir, "missing", manifestFile))
+	if err := registry.Add(table); err == nil {
+		t.Fatal("Add succeeded without a manifest directory")
+	}
+	if registry.Len() != 0 {
+		t.Fatalf("registry has %d tables after a failed Add, want 0", registry.Len())
+	}
+}
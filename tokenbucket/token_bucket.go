@@ -0,0 +1,81 @@
+package tokenbucket
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// STATE_SIZE je velicina serijalizovanog stanja: broj tokena i vreme poslednjeg punjenja (uint64, little endian)
+const STATE_SIZE = 16
+
+// TokenBucket dozvoljava najvise capacity zahteva po intervalu.
+// Kada od poslednjeg punjenja prodje ceo interval, bucket se ponovo puni do kraja.
+type TokenBucket struct {
+	capacity       uint64
+	refillInterval time.Duration
+	tokens         uint64
+	lastRefill     int64 // unix vreme u sekundama
+}
+
+// NewTokenBucket pravi pun bucket
+func NewTokenBucket(capacity uint64, refillInterval time.Duration) *TokenBucket {
+	return &TokenBucket{
+		capacity:       capacity,
+		refillInterval: refillInterval,
+		tokens:         capacity,
+		lastRefill:     time.Now().Unix(),
+	}
+}
+
+// Getteri
+func (tb *TokenBucket) GetCapacity() uint64              { return tb.capacity }
+func (tb *TokenBucket) GetRefillInterval() time.Duration { return tb.refillInterval }
+func (tb *TokenBucket) GetTokens() uint64                { return tb.tokens }
+func (tb *TokenBucket) GetLastRefill() int64             { return tb.lastRefill }
+
+// Refill puni bucket do kraja ako je od poslednjeg punjenja prosao ceo interval; vraca true ako ga je napunio
+func (tb *TokenBucket) Refill(now time.Time) bool {
+	if now.Unix()-tb.lastRefill < int64(tb.refillInterval/time.Second) {
+		return false
+	}
+	tb.tokens = tb.capacity
+	tb.lastRefill = now.Unix()
+	return true
+}
+
+// Take uzima jedan token; vraca false ako je bucket prazan do sledeceg punjenja
+func (tb *TokenBucket) Take(now time.Time) bool {
+	tb.Refill(now)
+	if tb.tokens == 0 {
+		return false
+	}
+	tb.tokens--
+	return true
+}
+
+// Serialize vraca stanje bucket-a (tokeni i vreme punjenja); kapacitet i interval dolaze iz configa
+func (tb *TokenBucket) Serialize() []byte {
+	data := make([]byte, STATE_SIZE)
+	binary.LittleEndian.PutUint64(data[0:8], tb.tokens)
+	binary.LittleEndian.PutUint64(data[8:16], uint64(tb.lastRefill))
+	return data
+}
+
+// Deserialize vraca bucket sa sacuvanim stanjem i zadatim kapacitetom i intervalom.
+// Ako je kapacitet u medjuvremenu smanjen, broj tokena se ogranicava na novi kapacitet.
+func Deserialize(data []byte, capacity uint64, refillInterval time.Duration) (*TokenBucket, error) {
+	if len(data) != STATE_SIZE {
+		return nil, fmt.Errorf("token bucket state has %d bytes, expected %d", len(data), STATE_SIZE)
+	}
+	tb := &TokenBucket{
+		capacity:       capacity,
+		refillInterval: refillInterval,
+		tokens:         binary.LittleEndian.Uint64(data[0:8]),
+		lastRefill:     int64(binary.LittleEndian.Uint64(data[8:16])),
+	}
+	if tb.tokens > capacity {
+		tb.tokens = capacity
+	}
+	return tb, nil
+}
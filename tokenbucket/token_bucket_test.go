@@ -0,0 +1,54 @@
+package tokenbucket
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTakeAndRefill(t *testing.T) {
+	tb := NewTokenBucket(2, time.Minute)
+	now := time.Unix(tb.GetLastRefill(), 0)
+	if !tb.Take(now) || !tb.Take(now) {
+		t.Fatal("a full bucket refused a token")
+	}
+	if tb.Take(now.Add(59 * time.Second)) {
+		t.Fatal("an empty bucket gave a token before the interval passed")
+	}
+	if !tb.Take(now.Add(time.Minute)) {
+		t.Fatal("the bucket was not refilled after the interval")
+	}
+	if tb.GetTokens() != 1 {
+		t.Fatalf("bucket has %d tokens after refill and take, want 1", tb.GetTokens())
+	}
+}
+
+func TestSerializeRoundTrip(t *testing.T) {
+	tb := NewTokenBucket(10, time.Minute)
+	tb.Take(time.Unix(tb.GetLastRefill(), 0))
+
+	restored, err := Deserialize(tb.Serialize(), 10, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.GetTokens() != 9 || restored.GetLastRefill() != tb.GetLastRefill() {
+		t.Fatalf("restored %d tokens refilled at %d, want 9 at %d", restored.GetTokens(), restored.GetLastRefill(), tb.GetLastRefill())
+	}
+
+	// smanjen kapacitet ogranicava sacuvane tokene
+	restored, err = Deserialize(tb.Serialize(), 4, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.GetTokens() != 4 {
+		t.Fatalf("restored %d tokens with capacity 4", restored.GetTokens())
+	}
+}
+
+func TestDeserializeCorrupted(t *testing.T) {
+	data := NewTokenBucket(10, time.Minute).Serialize()
+	for _, corrupted := range [][]byte{nil, data[:STATE_SIZE-1], append(data, 0)} {
+		if _, err := Deserialize(corrupted, 10, time.Minute); err == nil {
+			t.Fatalf("Deserialize accepted %d bytes", len(corrupted))
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+)
+
+// ValueType je tip strukture sacuvane pod korisnickim kljucem. Strukture (HLL, CMS, SimHash, Bloom filter)
+// su obicne vrednosti koje idu kroz isti WAL, memtable i SSTable put kao PUT i DELETE; prvi bajt vrednosti
+// je tip, pa komande jedne strukture odbijaju kljuc pod kojim je sacuvana druga.
+type ValueType byte
+
+const (
+	ValueHLL ValueType = iota + 1
+	ValueCMS
+	ValueSimHash
+	ValueBloom
+)
+
+func (t ValueType) String() string {
+	switch t {
+	case ValueHLL:
+		return "hll"
+	case ValueCMS:
+		return "cms"
+	case ValueSimHash:
+		return "simhash"
+	case ValueBloom:
+		return "bloom filter"
+	}
+	return fmt.Sprintf("value type %d", byte(t))
+}
+
+// encodeTyped dodaje tip ispred serijalizovane strukture
+func encodeTyped(t ValueType, data []byte) []byte {
+	return append([]byte{byte(t)}, data...)
+}
+
+// decodeTyped vraca serijalizovanu strukturu tipa t iz vrednosti kljuca key; nil vrednost znaci da kljuc ne postoji
+func decodeTyped(t ValueType, key string, value []byte) ([]byte, error) {
+	if value == nil {
+		return nil, fmt.Errorf("%s %q does not exist", t, key)
+	}
+	if len(value) == 0 || ValueType(value[0]) != t {
+		return nil, fmt.Errorf("key %q does not hold a %s", key, t)
+	}
+	return value[1:], nil
+}
+
+// getValue cita vrednost korisnickog kljuca; nil ako ne postoji ili je obrisan
+func (manager *Manager) getValue(key string) ([]byte, error) {
+	if err := checkUserKey(key); err != nil {
+		return nil, err
+	}
+	record, _, err := manager.find(key)
+	if err != nil || record == nil || record.GetTombstone() == 1 {
+		return nil, err
+	}
+	// prazna vrednost postoji, pa se razlikuje od nil
+	return append(make([]byte, 0, len(record.GetValue())), record.GetValue()...), nil
+}
+
+// updateValue cita vrednost korisnickog kljuca (nil ako ne postoji), menja je funkcijom update i upisuje rezultat
+// kao PUT; ako update vrati nil, kljuc se brise kao DELETE. Izmene idu jedna po jedna da se istovremena
+// azuriranja iste strukture ne bi pregazila.
+func (manager *Manager) updateValue(key string, update func(value []byte) ([]byte, error)) error {
+	if err := checkUserKey(key); err != nil {
+		return err
+	}
+	if err := checkKeySize(key); err != nil {
+		return err
+	}
+	manager.valueLock.Lock()
+	defer manager.valueLock.Unlock()
+
+	value, err := manager.getValue(key)
+	if err != nil {
+		return err
+	}
+	newValue, err := update(value)
+	if err != nil {
+		return err
+	}
+	if newValue == nil {
+		return manager.writeRecord(key, make([]byte, 0), 1)
+	}
+	return manager.writeRecord(key, newValue, 0)
+}
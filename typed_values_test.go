@@ -0,0 +1,213 @@
+package main
+
+import (
+	"math"
+	"os"
+	"project/memtable"
+	"testing"
+)
+
+// openTestManager otvara Manager bez ogranicenja broja zahteva u privremenom direktorijumu
+func openTestManager(t *testing.T) *Manager {
+	t.Helper()
+	useTempDir(t)
+	conf.RateLimitCapacity = 1 << 30
+	return NewManager(memtable.TypeSkipList)
+}
+
+// useTempDir prebacuje test u privremeni direktorijum; conf i radni direktorijum se vracaju na kraju testa
+func useTempDir(t *testing.T) {
+	t.Helper()
+	saved := *conf
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		os.Chdir(wd)
+		*conf = saved
+	})
+}
+
+func TestHLLStoredUnderUserKey(t *testing.T) {
+	m := openTestManager(t)
+	if err := m.HLLCreate("visitors", 10); err != nil {
+		t.Fatal(err)
+	}
+	for _, item := range []string{"a", "b", "a"} {
+		if err := m.HLLAdd("visitors", []byte(item)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := m.HLLCreate("visitors", 10); err == nil {
+		t.Fatal("HLLCreate replaced an existing HLL")
+	}
+
+	// HLL je obicna vrednost pod korisnickim kljucem, sa tipom u prvom bajtu
+	value, err := m.GET("visitors")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(value) == 0 || ValueType(value[0]) != ValueHLL {
+		t.Fatalf("GET visitors = %v, want a value tagged as hll", value)
+	}
+
+	if err := m.PUT("plain", []byte("text")); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.HLLAdd("plain", []byte("a")); err == nil {
+		t.Fatal("HLLAdd accepted a key holding a plain value")
+	}
+	if err := m.HLLDelete("plain"); err == nil {
+		t.Fatal("HLLDelete deleted a key holding a plain value")
+	}
+	if _, err := m.HLLEstimate("missing"); err == nil {
+		t.Fatal("HLLEstimate succeeded for a missing key")
+	}
+
+	// posle ponovnog otvaranja HLL se cita iz WAL-a
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+	m = NewManager(memtable.TypeSkipList)
+	defer m.Close()
+	estimate, err := m.HLLEstimate("visitors")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Round(estimate) != 2 {
+		t.Fatalf("HLLEstimate = %v after reopen, want 2", estimate)
+	}
+	if err := m.HLLDelete("visitors"); err != nil {
+		t.Fatal(err)
+	}
+	if value, err := m.GET("visitors"); err != nil || value != nil {
+		t.Fatalf("GET visitors after HLLDelete = %v, %v", value, err)
+	}
+}
+
+func TestCMSStoredUnderUserKey(t *testing.T) {
+	m := openTestManager(t)
+	defer m.Close()
+	if err := m.CMSCreate("events", 0.01, 0.01); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.CMSAdd("events", []byte("click"), 3); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.CMSAdd("events", []byte("click"), 2); err != nil {
+		t.Fatal(err)
+	}
+	count, err := m.CMSQuery("events", []byte("click"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count < 5 {
+		t.Fatalf("CMSQuery = %d, want at least 5", count)
+	}
+
+	// sketch ne moze da se napravi preko HLL-a, a CMS komande odbijaju kljuc sa HLL-om
+	if err := m.HLLCreate("visitors", 10); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.CMSCreate("visitors", 0.01, 0.01); err == nil {
+		t.Fatal("CMSCreate replaced an HLL")
+	}
+	if _, err := m.CMSQuery("visitors", []byte("click")); err == nil {
+		t.Fatal("CMSQuery accepted a key holding an HLL")
+	}
+	if _, err := m.HLLEstimate("events"); err == nil {
+		t.Fatal("HLLEstimate accepted a key holding a CMS")
+	}
+
+	if err := m.CMSDelete("events"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.CMSQuery("events", []byte("click")); err == nil {
+		t.Fatal("CMSQuery succeeded after CMSDelete")
+	}
+}
+
+func TestSimHashStoredUnderUserKey(t *testing.T) {
+	m := openTestManager(t)
+	defer m.Close()
+	if err := m.SimHashPut("doc1", "the quick brown fox jumps over the lazy dog"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.SimHashPut("doc2", "the quick brown fox jumps over the lazy cat"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.SimHashDistance("doc1", "doc2"); err != nil {
+		t.Fatal(err)
+	}
+	// isti tekst daje isti otisak
+	if err := m.SimHashPut("doc2", "the quick brown fox jumps over the lazy dog"); err != nil {
+		t.Fatal(err)
+	}
+	distance, err := m.SimHashDistance("doc1", "doc2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if distance != 0 {
+		t.Fatalf("SimHashDistance of equal texts = %d, want 0", distance)
+	}
+
+	// otisak ne menja obicnu vrednost, a SimHash komande je ne citaju
+	if err := m.PUT("plain", []byte("text")); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.SimHashPut("plain", "text"); err == nil {
+		t.Fatal("SimHashPut replaced a plain value")
+	}
+	if _, err := m.SimHashDistance("doc1", "plain"); err == nil {
+		t.Fatal("SimHashDistance accepted a key holding a plain value")
+	}
+
+	if err := m.SimHashDelete("doc1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.SimHashGet("doc1"); err == nil {
+		t.Fatal("SimHashGet succeeded after SimHashDelete")
+	}
+}
+
+func TestBloomStoredUnderUserKey(t *testing.T) {
+	m := openTestManager(t)
+	defer m.Close()
+	if err := m.BloomCreate("seen", 100, 0.01); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.BloomAdd("seen", []byte("a")); err != nil {
+		t.Fatal(err)
+	}
+	for item, want := range map[string]bool{"a": true, "b": false} {
+		found, err := m.BloomCheck("seen", []byte(item))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if found != want {
+			t.Fatalf("BloomCheck(%s) = %v, want %v", item, found, want)
+		}
+	}
+
+	if err := m.SimHashPut("doc", "text"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.BloomAdd("doc", []byte("a")); err == nil {
+		t.Fatal("BloomAdd accepted a key holding a SimHash fingerprint")
+	}
+	if _, err := m.SimHashGet("seen"); err == nil {
+		t.Fatal("SimHashGet accepted a key holding a Bloom filter")
+	}
+
+	// struktura je obicna vrednost, pa je DELETE brise kao i svaku drugu
+	if err := m.DELETE("seen"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.BloomCheck("seen", []byte("a")); err == nil {
+		t.Fatal("BloomCheck succeeded after DELETE")
+	}
+}
@@ -0,0 +1,110 @@
+package wal
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// groupCommit skuplja rekorde koje istovremeno upisuje vise gorutina u SYNC_ALWAYS rezimu, da bi
+// se za celu grupu jednom upisali blokovi i jednom uradio fsync.
+// Prvi pisac koji zatekne da niko ne radi fsync postaje lider: ceka najvise maxDelay (ili dok se ne
+// skupi maxBatch rekorda), pa radi Sync za sve do tada dodate rekorde. Ostali cekaju da fsync pokrije i njihov rekord.
+type groupCommit struct {
+	lock     sync.Mutex
+	cond     *sync.Cond
+	appended uint64 // redni broj poslednjeg dodatog rekorda
+	synced   uint64 // svi rekordi do ovog rednog broja su na disku
+	syncing  bool   // lider trenutno skuplja grupu ili radi fsync
+	err      error  // greska poslednjeg neuspelog fsync-a
+	errSeq   uint64 // rekordi do ovog rednog broja dobijaju err
+	maxDelay time.Duration
+	maxBatch int
+}
+
+func newGroupCommit() *groupCommit {
+	gc := &groupCommit{maxBatch: 1}
+	gc.cond = sync.NewCond(&gc.lock)
+	return gc
+}
+
+// SetGroupCommit postavlja koliko lider najduze ceka ostale pisce i posle koliko rekorda u grupi ne ceka dalje.
+// maxDelay 0 znaci da se ne ceka, ali se i dalje u jednu grupu spajaju rekordi dodati dok traje prethodni fsync.
+func (wal *WAL) SetGroupCommit(maxDelay time.Duration, maxBatch int) error {
+	if maxDelay < 0 {
+		return fmt.Errorf("WAL group commit delay must not be negative, got %v", maxDelay)
+	}
+	if maxBatch <= 0 {
+		return fmt.Errorf("WAL group commit batch size must be positive, got %d", maxBatch)
+	}
+	wal.group.lock.Lock()
+	defer wal.group.lock.Unlock()
+	wal.group.maxDelay = maxDelay
+	wal.group.maxBatch = maxBatch
+	return nil
+}
+
+// added belezi da je rekord sa rednim brojem seq dodat u baferpul i budi lidera koji skuplja grupu
+func (gc *groupCommit) added(seq uint64) {
+	gc.lock.Lock()
+	defer gc.lock.Unlock()
+	if seq > gc.appended {
+		gc.appended = seq
+	}
+	gc.cond.Broadcast()
+}
+
+// WaitDurable vraca se tek kada je rekord sa rednim brojem seq (iz AppendRecord) siguran na disku.
+// Odmah se vraca ako rezim nije SYNC_ALWAYS, jer tada AppendRecord vec upise rekord u fajl.
+func (wal *WAL) WaitDurable(seq uint64) error {
+	if wal.syncMode != SYNC_ALWAYS {
+		return nil
+	}
+	gc := wal.group
+	gc.lock.Lock()
+	defer gc.lock.Unlock()
+	for gc.synced < seq {
+		if gc.err != nil && seq <= gc.errSeq {
+			return gc.err
+		}
+		if gc.syncing {
+			gc.cond.Wait()
+			continue
+		}
+		gc.syncing = true
+		gc.waitForBatch()
+		target := gc.appended
+
+		// fsync se radi bez lock-a grupe, da bi novi pisci mogli da se prijave za sledecu grupu
+		gc.lock.Unlock()
+		err := wal.Sync()
+		gc.lock.Lock()
+
+		gc.syncing = false
+		if err != nil {
+			gc.err = fmt.Errorf("WAL group commit failed: %v", err)
+			gc.errSeq = target
+		} else if target > gc.synced {
+			gc.synced = target
+		}
+		gc.cond.Broadcast()
+	}
+	return nil
+}
+
+// waitForBatch ceka da se skupi maxBatch rekorda od poslednjeg fsync-a ili da istekne maxDelay; pozivalac drzi lock
+func (gc *groupCommit) waitForBatch() {
+	if gc.maxDelay == 0 {
+		return
+	}
+	deadline := time.Now().Add(gc.maxDelay)
+	timer := time.AfterFunc(gc.maxDelay, func() {
+		gc.lock.Lock()
+		defer gc.lock.Unlock()
+		gc.cond.Broadcast()
+	})
+	defer timer.Stop()
+	for gc.appended-gc.synced < uint64(gc.maxBatch) && time.Now().Before(deadline) {
+		gc.cond.Wait()
+	}
+}
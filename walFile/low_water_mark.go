@@ -0,0 +1,133 @@
+package wal
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// LOW_WATER_MARK_FILE je fajl (u direktorijumu WAL-a) sa polozajem poslednjeg rekorda koji je vec upisan u SSTable-ove.
+// Nalazi se van direktorijuma sa segmentima, da se ne bi mesao sa njima.
+const LOW_WATER_MARK_FILE = "LOW_WATER_MARK"
+
+// LOW_WATER_MARK_SIZE je velicina fajla: segment, blok i redni broj rekorda u bloku (uint64, little endian)
+const LOW_WATER_MARK_SIZE = 24
+
+// Position je polozaj rekorda u WAL-u: broj segmenta, broj bloka u segmentu (od 1) i redni broj rekorda u bloku.
+// Podeljen rekord je na polozaju svog prvog dela.
+type Position struct {
+	Segment uint64
+	Block   uint64
+	Index   uint64
+}
+
+// Before proverava da li je rekord na polozaju p upisan pre rekorda na polozaju other
+func (p Position) Before(other Position) bool {
+	if p.Segment != other.Segment {
+		return p.Segment < other.Segment
+	}
+	if p.Block != other.Block {
+		return p.Block < other.Block
+	}
+	return p.Index < other.Index
+}
+
+// Previous vraca polozaj koji je pre p, a nije pre nijednog rekorda upisanog pre p
+func (p Position) Previous() Position {
+	switch {
+	case p.Index > 0:
+		return Position{Segment: p.Segment, Block: p.Block, Index: p.Index - 1}
+	case p.Block > 1:
+		return Position{Segment: p.Segment, Block: p.Block - 1, Index: math.MaxUint64}
+	case p.Segment > 1:
+		return Position{Segment: p.Segment - 1, Block: math.MaxUint64, Index: math.MaxUint64}
+	}
+	return Position{}
+}
+
+// IsZero proverava da li polozaj nije postavljen (segmenti krecu od 1)
+func (p Position) IsZero() bool {
+	return p.Segment == 0
+}
+
+// segmentNumber vraca broj segmenta iz putanje oblika .../wal_NNN.log
+func segmentNumber(path string) (uint64, error) {
+	name := filepath.Base(path)
+	if !strings.HasPrefix(name, "wal_") || !strings.HasSuffix(name, ".log") {
+		return 0, fmt.Errorf("%s is not a WAL segment (expected wal_NNN.log)", path)
+	}
+	numberStr := strings.TrimSuffix(strings.TrimPrefix(name, "wal_"), ".log")
+	number, err := strconv.ParseUint(numberStr, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid segment number format in %s: %v", path, err)
+	}
+	return number, nil
+}
+
+// GetLastPosition vraca polozaj poslednjeg rekorda koji je upisan sa WriteRecord
+func (wal *WAL) GetLastPosition() Position {
+	return wal.lastPosition
+}
+
+// GetActiveSegment vraca broj segmenta u koji se trenutno pise
+func (wal *WAL) GetActiveSegment() (uint64, error) {
+	return segmentNumber(wal.activeSegmentPath)
+}
+
+// GetCurrentPosition vraca polozaj rekorda koji ce sledeci vratiti NextRecord
+func (wal *WAL) GetCurrentPosition() (Position, error) {
+	segment, err := segmentNumber(wal.currentRecordFilePath)
+	if err != nil {
+		return Position{}, err
+	}
+	return Position{Segment: segment, Block: wal.currentRecordBlockNum, Index: wal.currentRecordIndex}, nil
+}
+
+// SaveLowWaterMark trajno upisuje polozaj do kog (ukljucujuci i njega) su rekordi WAL-a upisani u SSTable-ove.
+// Fajl se menja upisom u .tmp pa rename, pa posle pada ostaje ili stara ili nova oznaka.
+// I .tmp i direktorijum se fsync-uju, pa je oznaka trajna pre nego sto se obrisu segmenti ispod nje.
+func (wal *WAL) SaveLowWaterMark(mark Position) error {
+	data := make([]byte, 0, LOW_WATER_MARK_SIZE)
+	data = binary.LittleEndian.AppendUint64(data, mark.Segment)
+	data = binary.LittleEndian.AppendUint64(data, mark.Block)
+	data = binary.LittleEndian.AppendUint64(data, mark.Index)
+
+	path := filepath.Join(wal.dir, LOW_WATER_MARK_FILE)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write WAL low-water mark: %v", err)
+	}
+	if err := syncFile(tmp); err != nil {
+		return fmt.Errorf("failed to write WAL low-water mark: %v", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to replace WAL low-water mark: %v", err)
+	}
+	if err := syncDir(wal.dir); err != nil {
+		return fmt.Errorf("failed to replace WAL low-water mark: %v", err)
+	}
+	return nil
+}
+
+// LoadLowWaterMark cita sacuvanu oznaku; ako fajl ne postoji vraca prazan polozaj (ceo WAL se ucitava)
+func (wal *WAL) LoadLowWaterMark() (Position, error) {
+	data, err := os.ReadFile(filepath.Join(wal.dir, LOW_WATER_MARK_FILE))
+	if os.IsNotExist(err) {
+		return Position{}, nil
+	}
+	if err != nil {
+		return Position{}, fmt.Errorf("failed to read WAL low-water mark: %v", err)
+	}
+	if len(data) != LOW_WATER_MARK_SIZE {
+		return Position{}, fmt.Errorf("WAL low-water mark is corrupted")
+	}
+	return Position{
+		Segment: binary.LittleEndian.Uint64(data),
+		Block:   binary.LittleEndian.Uint64(data[8:]),
+		Index:   binary.LittleEndian.Uint64(data[16:]),
+	}, nil
+}
@@ -0,0 +1,199 @@
+package wal
+
+import (
+	"fmt"
+	"os"
+	"project/blockmanager"
+)
+
+// RecoveryMode odredjuje sta NextRecord radi kada naidje na ostecen rekord, npr. blok koji je samo
+// delimicno upisan pre pada ili podeljen rekord kome nedostaju delovi:
+//   - truncate: staje na ostecenju; pozivalac posle ucitavanja odseca WAL na tom mestu (Truncate), pa se
+//     novi rekordi pisu odmah iza poslednjeg ispravnog
+//   - skip: preskace ostatak ostecenog bloka (velicine rekorda posle ostecenja nisu pouzdane) i nastavlja od sledeceg
+//   - fail: staje na ostecenju, a pozivalac vraca gresku i WAL ostaje nepromenjen
+type RecoveryMode string
+
+const (
+	RECOVERY_TRUNCATE RecoveryMode = "truncate"
+	RECOVERY_SKIP     RecoveryMode = "skip"
+	RECOVERY_FAIL     RecoveryMode = "fail"
+)
+
+// ParseRecoveryMode proverava ime rezima iz configa
+func ParseRecoveryMode(name string) (RecoveryMode, error) {
+	switch mode := RecoveryMode(name); mode {
+	case RECOVERY_TRUNCATE, RECOVERY_SKIP, RECOVERY_FAIL:
+		return mode, nil
+	}
+	return RECOVERY_FAIL, fmt.Errorf("unknown WAL recovery mode %q (expected truncate, skip or fail)", name)
+}
+
+func (wal *WAL) GetRecoveryMode() RecoveryMode {
+	return wal.recoveryMode
+}
+
+// SetRecoveryMode postavlja rezim oporavka; poziva se pre ucitavanja WAL-a
+func (wal *WAL) SetRecoveryMode(mode RecoveryMode) error {
+	if _, err := ParseRecoveryMode(string(mode)); err != nil {
+		return err
+	}
+	wal.recoveryMode = mode
+	return nil
+}
+
+// Corruption opisuje jedno ostecenje u WAL-u
+type Corruption struct {
+	Segment   string   // putanja segmenta
+	Position  Position // polozaj na kom je trebao da bude ispravan rekord
+	Offset    uint64   // pomeraj ostecenog rekorda od pocetka fajla segmenta
+	Discarded uint64   // u skip rezimu: koliko je bajtova preskoceno
+	Reason    string
+}
+
+func (c Corruption) Error() string {
+	return fmt.Sprintf("corrupt WAL record in segment %s, block %d, offset %d: %s", c.Segment, c.Position.Block, c.Offset, c.Reason)
+}
+
+// GetCorruptions vraca ostecenja na koja je NextRecord naisao od poslednjeg ResetCounter, redom kojim su nadjena
+func (wal *WAL) GetCorruptions() []Corruption {
+	return wal.corruptions
+}
+
+// addCorruption belezi ostecenje na rekordu index u bloku blockNum segmenta path;
+// offset je pomeraj rekorda od pocetka bloka
+func (wal *WAL) addCorruption(path string, blockNum, index, offset, blockSize, discarded uint64, reason string) {
+	segment, _ := segmentNumber(path)
+	wal.corruptions = append(wal.corruptions, Corruption{
+		Segment:   path,
+		Position:  Position{Segment: segment, Block: blockNum, Index: index},
+		Offset:    blockmanager.HEADER_SIZE + (blockNum-1)*blockSize + offset,
+		Discarded: discarded,
+		Reason:    reason,
+	})
+}
+
+// afterCorruption nastavlja citanje posle zabelezenog ostecenja: u skip rezimu od sledeceg bloka ako je
+// skipBlock postavljen (ostatak bloka se ne moze procitati), inace od mesta na kom je brojac; u ostalim rezimima staje
+func (wal *WAL) afterCorruption(blockManager *blockmanager.BlockManager, skipBlock bool) (*blockmanager.Record, bool) {
+	if wal.recoveryMode != RECOVERY_SKIP {
+		return nil, false
+	}
+	if skipBlock && !wal.nextBlock(wal.segmentBlockManager(wal.currentRecordFilePath, blockManager).GetBlockSize()) {
+		return nil, false
+	}
+	return wal.NextRecord(blockManager)
+}
+
+// nextBlock pomera brojac na pocetak sledeceg bloka (ili segmenta); vraca false ako ga nema.
+// Blok iza kraja fajla se ne cita, jer bi ga ReadBlock dopisao kao prazan, a prazan blok je kraj WAL-a.
+func (wal *WAL) nextBlock(blockSize uint64) bool {
+	nextStart := int64(blockmanager.HEADER_SIZE + wal.currentRecordBlockNum*blockSize)
+	info, err := os.Stat(wal.currentRecordFilePath)
+	if err == nil && info.Size() > nextStart && wal.currentRecordBlockNum+1 <= wal.blockNumber { //zbog hedera ide <=
+		wal.currentRecordBlockNum++
+	} else if wal.currentRecordFilePathIndex+1 < uint64(len(wal.segmentFilePaths)) {
+		wal.currentRecordFilePathIndex++
+		wal.currentRecordFilePath = wal.segmentFilePaths[wal.currentRecordFilePathIndex]
+		wal.currentRecordBlockNum = 1
+	} else {
+		return false
+	}
+	wal.currentRecordIndex = 0
+	return true
+}
+
+// skipRecord pomera brojac sa rekorda na kom je u bloku block na sledeci; vraca false ako ga nema
+func (wal *WAL) skipRecord(block *blockmanager.Block, blockSize uint64) bool {
+	if _, corrupt := block.GetCorruption(); corrupt || wal.currentRecordIndex+1 < uint64(len(block.GetRecords())) {
+		wal.currentRecordIndex++
+		return true
+	}
+	return wal.nextBlock(blockSize)
+}
+
+// recordOffset vraca pomeraj rekorda index od pocetka bloka
+func recordOffset(block *blockmanager.Block, index uint64) uint64 {
+	offset := uint64(0)
+	for _, record := range block.GetRecords()[:index] {
+		offset += record.GetRecordSize()
+	}
+	return offset
+}
+
+// Truncate odseca WAL na mestu ostecenja c: u bloku ostaju samo rekordi pre ostecenja, segment se skracuje
+// na kraj tog bloka, a kasniji segmenti se brisu, pa posle toga WAL postaje aktivan od tog segmenta.
+// Vraca broj odbacenih bajtova i obrisane segmente.
+func (wal *WAL) Truncate(c Corruption) (uint64, []string, error) {
+	wal.lock.Lock()
+	defer wal.lock.Unlock()
+	// u baferpulu su samo procitani blokovi, a osteceni se ne upisuju nazad
+	wal.blockManager.EmptyBufferPool()
+
+	blockManager := wal.segmentBlockManager(c.Segment, wal.blockManager)
+	block := blockManager.ReadBlock(c.Segment, c.Position.Block)
+	if block == nil {
+		return 0, nil, fmt.Errorf("failed to read block %d of WAL segment %s", c.Position.Block, c.Segment)
+	}
+	records := block.GetRecords()
+	if c.Position.Index < uint64(len(records)) {
+		records = records[:c.Position.Index]
+	}
+	block.SetRecords(records)
+	blockManager.EmptyBufferPool()
+
+	info, err := os.Stat(c.Segment)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to stat WAL segment: %v", err)
+	}
+	discarded := uint64(0)
+	if uint64(info.Size()) > c.Offset {
+		discarded = uint64(info.Size()) - c.Offset
+	}
+	end := int64(blockmanager.HEADER_SIZE + c.Position.Block*blockManager.GetBlockSize())
+	if info.Size() > end {
+		if err := os.Truncate(c.Segment, end); err != nil {
+			return 0, nil, fmt.Errorf("failed to truncate WAL segment: %v", err)
+		}
+	}
+	if err := syncFile(c.Segment); err != nil {
+		return 0, nil, err
+	}
+
+	removed := make([]string, 0)
+	after := false
+	for _, segment := range wal.segmentFilePaths {
+		if segment == c.Segment {
+			after = true
+			continue
+		}
+		if !after {
+			continue
+		}
+		if info, err := os.Stat(segment); err == nil {
+			discarded += uint64(info.Size())
+		}
+		if err := os.Remove(segment); err != nil {
+			return 0, nil, fmt.Errorf("failed to remove WAL segment: %v", err)
+		}
+		removed = append(removed, segment)
+	}
+	if err := syncDir(wal.segmentDir()); err != nil {
+		return 0, nil, err
+	}
+	wal.LoadSegments()
+	return discarded, removed, nil
+}
+
+// syncFile radi fsync fajla
+func syncFile(path string) error {
+	file, err := os.OpenFile(path, os.O_RDWR, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s for sync: %v", path, err)
+	}
+	defer file.Close()
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync %s: %v", path, err)
+	}
+	return nil
+}
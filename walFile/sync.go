@@ -0,0 +1,115 @@
+package wal
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// SyncMode odredjuje kada se segmenti WAL-a fsync-uju na disk.
+// U svim rezimima WriteRecord prenosi rekord u fajl pre povratka, pa potvrdjen upis prezivljava pad programa.
+// Rezim odredjuje sta prezivljava pad sistema ili nestanak struje:
+//   - always: fsync pre povratka iz WriteRecord, prezivljavaju svi potvrdjeni upisi
+//   - interval: fsync u pozadini na svakih syncInterval, mogu se izgubiti upisi iz poslednjeg intervala
+//   - none: fsync radi samo Close, do tada operativni sistem sam odlucuje kada ce upisati podatke
+type SyncMode string
+
+const (
+	SYNC_ALWAYS   SyncMode = "always"
+	SYNC_INTERVAL SyncMode = "interval"
+	SYNC_NONE     SyncMode = "none"
+)
+
+// ParseSyncMode proverava ime rezima iz configa
+func ParseSyncMode(name string) (SyncMode, error) {
+	switch mode := SyncMode(name); mode {
+	case SYNC_ALWAYS, SYNC_INTERVAL, SYNC_NONE:
+		return mode, nil
+	}
+	return SYNC_NONE, fmt.Errorf("unknown WAL sync mode %q (expected always, interval or none)", name)
+}
+
+func (wal *WAL) GetSyncMode() SyncMode {
+	return wal.syncMode
+}
+
+// SetSyncPolicy postavlja rezim fsync-a; za interval pokrece gorutinu koja radi fsync na svakih interval.
+// Poziva se jednom, pre prvog upisa.
+func (wal *WAL) SetSyncPolicy(mode SyncMode, interval time.Duration) error {
+	if _, err := ParseSyncMode(string(mode)); err != nil {
+		return err
+	}
+	if mode == SYNC_INTERVAL && interval <= 0 {
+		return fmt.Errorf("WAL sync interval must be positive, got %v", interval)
+	}
+	wal.syncMode = mode
+	wal.syncInterval = interval
+	if mode == SYNC_INTERVAL {
+		wal.stopSync = make(chan struct{})
+		wal.syncDone.Add(1)
+		go wal.syncLoop()
+	}
+	return nil
+}
+
+// syncLoop radi fsync na svakih syncInterval dok se ne zatvori stopSync
+func (wal *WAL) syncLoop() {
+	defer wal.syncDone.Done()
+	ticker := time.NewTicker(wal.syncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-wal.stopSync:
+			return
+		case <-ticker.C:
+			if err := wal.Sync(); err != nil {
+				fmt.Printf("WAL sync failed: %v\n", err)
+			}
+		}
+	}
+}
+
+// Sync upisuje baferpul u segmente i radi fsync svih segmenata u koje je pisano od prethodnog Sync-a
+func (wal *WAL) Sync() error {
+	wal.lock.Lock()
+	defer wal.lock.Unlock()
+	return wal.sync()
+}
+
+// sync radi isto sto i Sync; pozivalac drzi lock
+func (wal *WAL) sync() error {
+	if err := wal.blockManager.Sync(); err != nil {
+		return err
+	}
+	// nov segment je siguran tek kada je i njegovo ime upisano u direktorijum
+	if wal.dirUnsynced {
+		if err := syncDir(wal.segmentDir()); err != nil {
+			return err
+		}
+		wal.dirUnsynced = false
+	}
+	return nil
+}
+
+// Close zaustavlja pozadinski fsync i radi poslednji, pa su posle njega svi upisi na disku u svakom rezimu
+func (wal *WAL) Close() error {
+	if wal.stopSync != nil {
+		close(wal.stopSync)
+		wal.syncDone.Wait()
+		wal.stopSync = nil
+	}
+	return wal.Sync()
+}
+
+// syncDir radi fsync direktorijuma, da bi napravljeni ili obrisani fajlovi u njemu bili trajni
+func syncDir(path string) error {
+	dir, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open WAL directory for sync: %v", err)
+	}
+	defer dir.Close()
+	if err := dir.Sync(); err != nil {
+		return fmt.Errorf("failed to sync WAL directory: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,626 @@
+/*
+Postoje geteri i seteri za sve atribute wal strukutre
+
+func NewWal(blockNum uint64, dir string, blockManager *blockmanager.BlockManager) *WAL - pravi se novi wal, segmenti su u dir/WAL i svaki ima blockNum blokova
+
+func (wal *WAL) WriteRecord(record *blockmanager.Record, blockManager *blockmanager.BlockManager) - pisanje rekorda u wal
+
+func (wal *WAL) AppendRecord(...) (uint64, error) i func (wal *WAL) WaitDurable(seq uint64) error - WriteRecord u dva koraka,
+da bi pisac izmedju njih pustio druge pisce i da bi njihovi rekordi isli u isti fsync (group commit, vidi group_commit.go)
+
+func (wal *WAL) CreateSegment(blockManager *blockmanager.BlockManager) - pravljenje novog segmenta
+
+func (wal *WAL) LoadSegments() - ucitavanje svih segmenata, poziva se u funkciji newwal
+
+func (wal *WAL) NextRecord(blockManager *blockmanager.BlockManager) *blockmanager.Record -funkcija koja ide redom i cita rekord jedan po jedan
+jedina funkcija bi trebala da bude, vracanje stanja, kada se ucitaju segmenti kada se pokrene wal da se ide redom sa ovom funkcijom i da se izvrsavaju operacije
+nema posebne funkcije koja to radi, ali samo se pokrene beskonacna petlja i izvrte se svi rekordi.
+Na ostecenom rekordu staje ili ga preskace, zavisno od rezima oporavka (vidi recovery.go)
+
+func (wal *WAL) SetRecoveryMode(mode RecoveryMode) error - sta NextRecord radi kada naidje na ostecen rekord: truncate, skip ili fail
+
+func (wal *WAL) GetCorruptions() []Corruption - ostecenja na koja je NextRecord naisao od poslednjeg ResetCounter
+
+func (wal *WAL) Truncate(c Corruption) (uint64, []string, error) - odseca WAL na mestu ostecenja
+
+func (wal *WAL) ResetCounter()- pomocna funkcija da NextRecord funkcija krene od pocetka
+
+func (wal *WAL) DeleteSegments(index uint64) error - brisu se svi segmenti ciji je broj manji od
+
+func (wal *WAL) GetLastPosition() Position - polozaj poslednjeg upisanog rekorda, Manager ga pamti za low-water mark (vidi low_water_mark.go)
+
+func (wal *WAL) GetCurrentPosition() (Position, error) - polozaj rekorda koji ce sledeci vratiti NextRecord
+
+func (wal *WAL) GetActiveSegment() (uint64, error) - broj segmenta u koji se trenutno pise
+
+func (wal *WAL) SetSyncPolicy(mode SyncMode, interval time.Duration) error - kada se radi fsync segmenata: always, interval ili none (vidi sync.go)
+
+func (wal *WAL) Sync() error - fsync svih segmenata u koje je pisano
+
+func (wal *WAL) Close() error - zaustavlja pozadinski fsync i radi poslednji
+*/
+
+package wal
+
+import (
+	"encoding/binary"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"project/blockmanager"
+	"sort"
+	"sync"
+	"time"
+)
+
+type WAL struct {
+	dir               string //direktorijum WAL-a, segmenti su u dir/WAL
+	blockNumber       uint64
+	segmentFilePaths  []string
+	activeSegmentPath string //aktivan segment za pisanje
+	blockManager      *blockmanager.BlockManager
+	numberofRecords   uint64
+
+	currentRecordIndex         uint64 //pise u dokumentaciji da wal cita rekord po rekord pa mi treba ovo
+	currentRecordBlockNum      uint64
+	currentRecordFilePath      string
+	currentRecordFilePathIndex uint64
+
+	lastPosition Position //polozaj poslednjeg upisanog rekorda, kod podeljenog rekorda polozaj prvog dela
+
+	// lock cuva baferpul dok se u njega pise, od gorutine koja radi fsync (vidi sync.go)
+	lock         sync.Mutex
+	syncMode     SyncMode
+	syncInterval time.Duration
+	dirUnsynced  bool // napravljen je segment, pa i direktorijum treba fsync
+	stopSync     chan struct{}
+	syncDone     sync.WaitGroup
+	group        *groupCommit
+	appendSeq    uint64 // redni broj poslednjeg rekorda dodatog sa AppendRecord
+
+	recoveryMode RecoveryMode
+	corruptions  []Corruption // ostecenja na koja je NextRecord naisao od poslednjeg ResetCounter
+	readWhole    bool         // NextRecord je od poslednjeg ResetCounter procitao ceo ili prvi deo rekorda
+}
+
+// Setters for WAL struct
+func (wal *WAL) SetBlockNumber(num uint64) {
+	wal.blockNumber = num
+}
+
+func (wal *WAL) SetSegmentFilePaths(paths []string) {
+	wal.segmentFilePaths = paths
+}
+
+func (wal *WAL) SetActiveSegmentPath(path string) {
+	wal.activeSegmentPath = path
+}
+
+func (wal *WAL) SetBlockManager(manager *blockmanager.BlockManager) {
+	wal.blockManager = manager
+}
+
+func (wal *WAL) SetNumberOfRecords(num uint64) {
+	wal.numberofRecords = num
+}
+
+func (wal *WAL) SetCurrentRecordIndex(idx uint64) {
+	wal.currentRecordIndex = idx
+}
+
+func (wal *WAL) SetCurrentRecordBlockNum(num uint64) {
+	wal.currentRecordBlockNum = num
+}
+
+func (wal *WAL) SetCurrentRecordFilePath(path string) {
+	wal.currentRecordFilePath = path
+}
+
+func (wal *WAL) SetCurrentRecordFilePathIndex(idx uint64) {
+	wal.currentRecordFilePathIndex = idx
+}
+
+func (wal *WAL) GetBlockNum() uint64 {
+	return wal.blockNumber
+}
+func (wal *WAL) GetSegmentFilePaths() []string {
+	return wal.segmentFilePaths
+}
+func (wal *WAL) GetActiveSegmentPath() string {
+	return wal.activeSegmentPath
+}
+func (wal *WAL) GetBlockManager() *blockmanager.BlockManager {
+	return wal.blockManager
+}
+func (wal *WAL) GetNumberOfRecords() uint64 {
+	return wal.numberofRecords
+}
+func (wal *WAL) GetCurrentRecordIndex() uint64 {
+	return wal.currentRecordIndex
+}
+func (wal *WAL) GetCurrentRecordBlockNum() uint64 {
+	return wal.currentRecordBlockNum
+}
+func (wal *WAL) GetCurrentRecordFilePath() string {
+	return wal.currentRecordFilePath
+}
+func (wal *WAL) GetCurrentRecordFilePathIndex() uint64 {
+	return wal.currentRecordFilePathIndex
+}
+
+// WriteRecord upisuje rekord u WAL i vraca se kada je upis trajan koliko SyncMode obecava (vidi sync.go)
+func (wal *WAL) WriteRecord(record *blockmanager.Record, blockManager *blockmanager.BlockManager) error {
+	seq, err := wal.AppendRecord(record, blockManager)
+	if err != nil {
+		return err
+	}
+	return wal.WaitDurable(seq)
+}
+
+// AppendRecord dodaje rekord u aktivan segment i vraca njegov redni broj za WaitDurable.
+// U SYNC_ALWAYS rezimu blok ostaje u baferpulu, pa ga zajedno sa ostalim rekordima grupe upisuje i fsync-uje lider
+// (vidi group_commit.go); u ostalim rezimima se odmah prenosi u fajl i rekord posle povratka prezivljava pad programa.
+func (wal *WAL) AppendRecord(record *blockmanager.Record, blockManager *blockmanager.BlockManager) (uint64, error) {
+	wal.lock.Lock()
+	defer wal.lock.Unlock()
+	if err := wal.writeRecord(record, blockManager); err != nil {
+		return 0, err
+	}
+	wal.appendSeq++
+	if wal.syncMode != SYNC_ALWAYS {
+		blockManager.EmptyBufferPool()
+	}
+	wal.group.added(wal.appendSeq)
+	return wal.appendSeq, nil
+}
+
+// writeRecord upisuje rekord u blok u baferpulu, deli ga ako ne staje u blok i pravi nov segment kada je aktivan pun
+func (wal *WAL) writeRecord(record *blockmanager.Record, blockManager *blockmanager.BlockManager) error {
+	file, err := os.OpenFile(wal.activeSegmentPath, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open WAL file: %v", err)
+	}
+	defer file.Close()
+
+	end, err := file.Seek(0, 2)
+	if err != nil {
+		return fmt.Errorf("failed to seek to end of WAL file: %v", err)
+	}
+	lastBlockNumber := (end - blockmanager.HEADER_SIZE) / int64(blockManager.GetBlockSize())
+
+	block := blockManager.ReadBlock(wal.activeSegmentPath, uint64(lastBlockNumber))
+
+	recordsSizeSum := uint64(0)
+	for _, record := range block.GetRecords() {
+		recordsSizeSum += record.GetRecordSize()
+	}
+	spaceLeft := blockManager.GetBlockSize() - recordsSizeSum
+	records := block.GetRecords()
+	//slucaj za deljenje rekorda
+
+	if len(records) == 0 && spaceLeft < record.GetRecordSize() {
+		return wal.writeDividedRecord(record, blockManager)
+	}
+
+	if spaceLeft >= record.GetRecordSize() {
+
+		if err := wal.setLastPosition(block, len(records)); err != nil {
+			return err
+		}
+		records = append(records, record)
+		block.SetRecords(records)
+		wal.numberofRecords++
+		return nil
+	} else if lastBlockNumber+1 <= int64(wal.blockNumber) {
+		block := blockManager.ReadBlock(wal.activeSegmentPath, uint64(lastBlockNumber+1))
+		recordsSizeSum = uint64(0)
+		for _, record := range block.GetRecords() {
+			recordsSizeSum += record.GetRecordSize()
+		}
+		spaceLeft = blockManager.GetBlockSize() - recordsSizeSum
+		records := block.GetRecords()
+		//slucaj za deljenje rekorda
+
+		if len(records) == 0 && spaceLeft < record.GetRecordSize() {
+			return wal.writeDividedRecord(record, blockManager)
+		}
+		// records = block.GetRecords()
+		if err := wal.setLastPosition(block, len(records)); err != nil {
+			return err
+		}
+		records = append(records, record)
+		block.SetRecords(records)
+		wal.numberofRecords++
+		return nil
+	} else {
+		err := wal.CreateSegment(wal.blockManager)
+		if err != nil {
+			return fmt.Errorf("failed to create new segment: %v", err)
+		}
+		// novi segment je prazan, pa se rekord upisuje ispocetka (i deli ako ne staje u blok)
+		return wal.writeRecord(record, blockManager)
+	}
+}
+
+// writeDividedRecord upisuje rekord koji ne staje u blok deo po deo; polozaj rekorda je polozaj prvog dela
+func (wal *WAL) writeDividedRecord(record *blockmanager.Record, blockManager *blockmanager.BlockManager) error {
+	dividedRecords := record.DivideRecord(blockManager.GetBlockSize())
+	var first Position
+	for i, rec := range dividedRecords {
+		err := wal.writeRecord(rec, blockManager)
+		if err != nil {
+			return fmt.Errorf("failed to write divided record: %v", err)
+		}
+		if i == 0 {
+			first = wal.lastPosition
+		}
+	}
+	wal.lastPosition = first
+	return nil
+}
+
+// setLastPosition pamti polozaj rekorda koji se upisuje u aktivan segment, u dati blok na mesto index
+func (wal *WAL) setLastPosition(block *blockmanager.Block, index int) error {
+	segment, err := segmentNumber(wal.activeSegmentPath)
+	if err != nil {
+		return err
+	}
+	wal.lastPosition = Position{Segment: segment, Block: block.GetBlockNumber(), Index: uint64(index)}
+	return nil
+}
+
+// segmentDir vraca direktorijum u kom su segmenti
+func (wal *WAL) segmentDir() string {
+	return filepath.Join(wal.dir, "WAL")
+}
+
+func (wal *WAL) CreateSegment(blockManager *blockmanager.BlockManager) error {
+	if len(wal.segmentFilePaths) == 0 {
+		err := os.MkdirAll(wal.segmentDir(), 0755)
+		if err != nil {
+			return fmt.Errorf("failed to create WAL directory: %v", err)
+		}
+		newName := filepath.Join(wal.segmentDir(), "wal_001.log")
+		file, err := os.Create(newName)
+		if err != nil {
+			return fmt.Errorf("failed to create WAL file: %v", err)
+		}
+		file.Close()
+		wal.activeSegmentPath = newName
+		wal.segmentFilePaths = append(wal.segmentFilePaths, newName)
+		wal.dirUnsynced = true
+		blockmanager.WriteHeader(newName, blockManager.GetBlockSize())
+		wal.ResetCounter()
+		return nil
+	}
+	lastSegmentName := wal.segmentFilePaths[len(wal.segmentFilePaths)-1]
+	lastNumber, err := segmentNumber(lastSegmentName)
+	if err != nil {
+		return err
+	}
+	newName := filepath.Join(wal.segmentDir(), fmt.Sprintf("wal_%03d.log", lastNumber+1))
+	file, err := os.Create(newName)
+	if err != nil {
+		return fmt.Errorf("failed to create new WAL segment: %v", err)
+	}
+	file.Close()
+	wal.activeSegmentPath = newName
+	wal.segmentFilePaths = append(wal.segmentFilePaths, newName)
+	wal.dirUnsynced = true
+	blockmanager.WriteHeader(wal.activeSegmentPath, blockManager.GetBlockSize())
+	wal.ResetCounter()
+	return nil
+}
+
+func NewWal(blockNum uint64, dir string, blockManager *blockmanager.BlockManager) *WAL {
+	wal := &WAL{dir: dir, syncMode: SYNC_NONE, recoveryMode: RECOVERY_FAIL, group: newGroupCommit(), blockNumber: blockNum, currentRecordIndex: 0, currentRecordBlockNum: 1, currentRecordFilePathIndex: 0, blockManager: blockManager, numberofRecords: 0}
+	wal.LoadSegments()
+	if len(wal.activeSegmentPath) == 0 {
+		err := wal.CreateSegment(blockManager)
+		if err != nil {
+			log.Fatalf("Failed to create initial WAL segment: %v", err)
+		}
+	}
+	wal.currentRecordFilePath = wal.segmentFilePaths[0]
+	return wal
+}
+
+func (wal *WAL) LoadSegments() {
+
+	wal.numberofRecords = 0
+	os.MkdirAll(wal.segmentDir(), 0755)
+	wal.segmentFilePaths = make([]string, 0)
+	entries, err := os.ReadDir(wal.segmentDir())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// ostali fajlovi u direktorijumu (npr. kopije ili ostaci alata) nisu segmenti i ne citaju se
+	numbers := make(map[string]uint64)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		filePath := filepath.Join(wal.segmentDir(), entry.Name())
+		number, err := segmentNumber(filePath)
+		if err != nil {
+			fmt.Printf("Ignoring %s in WAL directory: not a segment\n", entry.Name())
+			continue
+		}
+		numbers[filePath] = number
+		wal.segmentFilePaths = append(wal.segmentFilePaths, filePath)
+	}
+	// po broju, a ne po imenu, jer wal_1000.log ide posle wal_999.log
+	sort.Slice(wal.segmentFilePaths, func(i, j int) bool {
+		return numbers[wal.segmentFilePaths[i]] < numbers[wal.segmentFilePaths[j]]
+	})
+
+	if len(wal.segmentFilePaths) == 0 {
+		wal.currentRecordFilePath = ""
+		wal.currentRecordFilePathIndex = 0
+		wal.currentRecordBlockNum = 1
+		wal.currentRecordIndex = 0
+		return
+	}
+	wal.activeSegmentPath = wal.segmentFilePaths[len(wal.segmentFilePaths)-1]
+	wal.ResetCounter()
+
+	for {
+		rec, hasNext := wal.NextRecord(wal.blockManager)
+		if !hasNext {
+			if rec != nil {
+				wal.numberofRecords++
+			}
+			break
+		}
+		wal.numberofRecords++
+	}
+}
+func (wal *WAL) ResetCounter() {
+	wal.corruptions = nil
+	wal.readWhole = false
+	if len(wal.segmentFilePaths) == 0 {
+		wal.currentRecordFilePathIndex = 0
+		wal.currentRecordBlockNum = 1
+		wal.currentRecordIndex = 0
+		wal.currentRecordFilePath = ""
+		return
+	}
+
+	wal.currentRecordFilePathIndex = 0
+	wal.currentRecordBlockNum = 1
+	wal.currentRecordIndex = 0
+	wal.currentRecordFilePath = wal.segmentFilePaths[0]
+}
+func (wal *WAL) NextRecord(blockManager *blockmanager.BlockManager) (*blockmanager.Record, bool) {
+	if len(wal.segmentFilePaths) == 0 {
+		return nil, false
+	}
+
+	if wal.currentRecordFilePath == "" {
+		return nil, false
+	}
+
+	tempBlockManager := wal.segmentBlockManager(wal.currentRecordFilePath, blockManager)
+	block := tempBlockManager.ReadBlock(wal.currentRecordFilePath, wal.currentRecordBlockNum)
+	if block == nil {
+		return nil, false
+	}
+	// ostecen rekord na mestu sledeceg, sta dalje odlucuje rezim oporavka (vidi recovery.go)
+	if offset, corrupt := block.GetCorruption(); corrupt && wal.currentRecordIndex >= uint64(len(block.GetRecords())) {
+		wal.addCorruption(wal.currentRecordFilePath, wal.currentRecordBlockNum, wal.currentRecordIndex, offset,
+			tempBlockManager.GetBlockSize(), tempBlockManager.GetBlockSize()-offset, "damaged record (checksum or size mismatch)")
+		return wal.afterCorruption(blockManager, true)
+	}
+	if len(block.GetRecords()) == 0 {
+		if wal.currentRecordFilePathIndex >= uint64(len(wal.segmentFilePaths)) {
+			return nil, false
+		}
+		wal.currentRecordFilePathIndex = 0
+		wal.currentRecordFilePath = wal.segmentFilePaths[wal.currentRecordFilePathIndex]
+		wal.currentRecordBlockNum = 1
+		wal.currentRecordIndex = 0
+		return nil, false
+	}
+	if wal.currentRecordIndex >= uint64(len(block.GetRecords())) {
+		return nil, false
+	}
+	record := block.GetRecords()[wal.currentRecordIndex]
+
+	// srednji ili poslednji deo bez prvog: na pocetku WAL-a je ostatak rekorda ciji je prvi deo bio
+	// u obrisanom segmentu (vec je u SSTable-ovima), a inace je prvi deo bio u ostecenom delu WAL-a
+	if record.GetRecordType() == 2 || record.GetRecordType() == 3 {
+		if !wal.readWhole {
+			if !wal.skipRecord(block, tempBlockManager.GetBlockSize()) {
+				return nil, false
+			}
+			return wal.NextRecord(blockManager)
+		}
+		wal.addCorruption(wal.currentRecordFilePath, wal.currentRecordBlockNum, wal.currentRecordIndex, recordOffset(block, wal.currentRecordIndex),
+			tempBlockManager.GetBlockSize(), record.GetRecordSize(), "part of a divided record without its first part")
+		if wal.recoveryMode != RECOVERY_SKIP || !wal.skipRecord(block, tempBlockManager.GetBlockSize()) {
+			return nil, false
+		}
+		return wal.NextRecord(blockManager)
+	}
+
+	wal.readWhole = true
+	if record.GetRecordType() == 1 {
+		path, blockNum, index := wal.currentRecordFilePath, wal.currentRecordBlockNum, wal.currentRecordIndex
+		connected, partsSize := wal.ConnectDividedRecord(record, block)
+		if connected == nil {
+			// ostecenje je na mestu prvog dela, a citanje se nastavlja od mesta gde deo nije nadjen
+			wal.addCorruption(path, blockNum, index, recordOffset(block, index),
+				tempBlockManager.GetBlockSize(), partsSize, "divided record is missing parts")
+			return wal.afterCorruption(blockManager, false)
+		}
+		record = connected
+		// poslednji deo je u drugom bloku (mozda i segmentu), pa se dalje ide od njega
+		tempBlockManager = wal.segmentBlockManager(wal.currentRecordFilePath, blockManager)
+		block = tempBlockManager.ReadBlock(wal.currentRecordFilePath, wal.currentRecordBlockNum)
+	}
+
+	// iza poslednjeg rekorda ostecenog bloka ostaje se u bloku, da bi sledeci poziv naisao na ostecenje
+	_, corrupt := block.GetCorruption()
+	if wal.currentRecordIndex+1 < uint64(len(block.GetRecords())) || corrupt {
+		wal.currentRecordIndex++
+	} else if wal.currentRecordBlockNum+1 <= wal.blockNumber { //zbog hedera ide <=
+		wal.currentRecordBlockNum++
+		wal.currentRecordIndex = 0
+	} else if wal.currentRecordFilePathIndex+1 < uint64(len(wal.segmentFilePaths)) {
+		wal.currentRecordFilePathIndex++
+		if wal.currentRecordFilePathIndex < uint64(len(wal.segmentFilePaths)) {
+			wal.currentRecordFilePath = wal.segmentFilePaths[wal.currentRecordFilePathIndex] //nije potreban moze i samo sa indeksom
+		}
+		wal.currentRecordBlockNum = 1
+		wal.currentRecordIndex = 0
+	} else {
+
+		return record, false
+	}
+
+	return record, true
+
+}
+
+// segmentBlockManager vraca menadzer sa velicinom bloka iz hedera segmenta, ako je segment pisan sa drugacijom
+func (wal *WAL) segmentBlockManager(path string, blockManager *blockmanager.BlockManager) *blockmanager.BlockManager {
+	header := blockmanager.ReadHeader(path)
+	if header != nil {
+		for _, record := range header.GetRecords() {
+			if record.GetKey() == "block size" {
+				valueUint := binary.LittleEndian.Uint64(record.GetValue())
+				if valueUint != blockManager.GetBlockSize() {
+					return blockmanager.NewBlockManager(blockManager.GetBufferPool(), valueUint, blockManager.GetBufferPoolSize())
+				}
+			}
+		}
+	}
+	return blockManager
+}
+
+// ConnectDividedRecord spaja delove podeljenog rekorda u jedan rekord i ostavlja brojac na poslednjem delu.
+// Ako neki deo nedostaje ili je ostecen vraca nil, brojac ostaje na mestu gde je deo trebao da bude,
+// a drugi rezultat je ukupna velicina delova koji su nadjeni.
+func (wal *WAL) ConnectDividedRecord(firstPart *blockmanager.Record, currentBlock *blockmanager.Block) (*blockmanager.Record, uint64) {
+	record := firstPart
+	block := currentBlock
+	partsSize := record.GetRecordSize()
+	value := make([]byte, 0)
+	value = append(value, record.GetValue()...)
+	for record.GetRecordType() != 3 {
+		if wal.currentRecordIndex+1 < uint64(len(block.GetRecords())) {
+			wal.currentRecordIndex++
+		} else if _, corrupt := block.GetCorruption(); corrupt {
+			wal.currentRecordIndex++
+			return nil, partsSize
+		} else if wal.currentRecordBlockNum+1 <= wal.blockNumber { //zbog hedera ide <=
+			wal.currentRecordBlockNum++
+			wal.currentRecordIndex = 0
+			block = wal.segmentBlockManager(wal.currentRecordFilePath, wal.blockManager).ReadBlock(wal.currentRecordFilePath, wal.currentRecordBlockNum)
+		} else if wal.currentRecordFilePathIndex+1 < uint64(len(wal.segmentFilePaths)) {
+			wal.currentRecordFilePathIndex++
+			wal.currentRecordFilePath = wal.segmentFilePaths[wal.currentRecordFilePathIndex] //nije potreban moze i samo sa indeksom
+			wal.currentRecordBlockNum = 1
+			wal.currentRecordIndex = 0
+			block = wal.segmentBlockManager(wal.currentRecordFilePath, wal.blockManager).ReadBlock(wal.currentRecordFilePath, wal.currentRecordBlockNum)
+		} else {
+			// kraj WAL-a pre poslednjeg dela
+			wal.currentRecordIndex++
+			return nil, partsSize
+		}
+		if block == nil || wal.currentRecordIndex >= uint64(len(block.GetRecords())) {
+			return nil, partsSize
+		}
+		record = block.GetRecords()[wal.currentRecordIndex]
+		// posle prvog dela moze doci samo srednji ili poslednji deo
+		if record.GetRecordType() != 2 && record.GetRecordType() != 3 {
+			return nil, partsSize
+		}
+		partsSize += record.GetRecordSize()
+		value = append(value, record.GetValue()...)
+	}
+	//return blockmanager.SetRec(0, firstPart.GetLogNum(), firstPart.GetTombstone(), firstPart.GetKeySize(), uint64(len(value)), firstPart.GetKey(), value)
+	// Rekonstruišemo finalni FULL record (recordType = 0) bez menjanja originalnog timestamp-a
+	// NE koristimo SetRec jer bi on kreirao novi timestamp i time narušio redosled verzija pri replay-u WAL-a
+	r := &blockmanager.Record{}
+	r.SetTimeStamp(firstPart.GetTimeStamp())
+	r.SetRecordType(0)
+	r.SetLogNum(firstPart.GetLogNum())
+	r.SetTombstone(firstPart.GetTombstone())
+	r.SetKeySize(firstPart.GetKeySize())
+	r.SetValueSize(uint64(len(value)))
+	r.SetKey(firstPart.GetKey())
+	r.SetValue(value)
+	r.SetRecordSize(4 + 8 + 2 + 8 + 8 + 1 + 8 + 8 + r.GetKeySize() + r.GetValueSize())
+
+	// Izračun CRC (isti način kao u SetRec / RecordPart)
+	data := make([]byte, 0)
+	recordSizeByte := make([]byte, 8)
+	binary.LittleEndian.PutUint64(recordSizeByte, r.GetRecordSize())
+	recordTypeByte := make([]byte, 2)
+	binary.LittleEndian.PutUint16(recordTypeByte, uint16(r.GetRecordType()))
+	logByte := make([]byte, 8)
+	binary.LittleEndian.PutUint64(logByte, r.GetLogNum())
+	tsByte := make([]byte, 8)
+	binary.LittleEndian.PutUint64(tsByte, r.GetTimeStamp())
+	ksByte := make([]byte, 8)
+	binary.LittleEndian.PutUint64(ksByte, r.GetKeySize())
+	vsByte := make([]byte, 8)
+	binary.LittleEndian.PutUint64(vsByte, r.GetValueSize())
+	data = append(data, recordSizeByte...)
+	data = append(data, recordTypeByte...)
+	data = append(data, logByte...)
+	data = append(data, tsByte...)
+	data = append(data, byte(r.GetTombstone()))
+	data = append(data, ksByte...)
+	data = append(data, vsByte...)
+	data = append(data, []byte(r.GetKey())...)
+	data = append(data, r.GetValue()...)
+	r.SetCRCData(blockmanager.CRC32(data))
+	return r, partsSize
+	// logNum je isti kao kod firstPart; svi delovi dele isti logNum tako da je deterministično.
+}
+
+// DeleteSegments brise segmente ciji je broj manji od index i izbacuje ih iz liste segmenata, bez ponovnog citanja WAL-a.
+// Broj rekorda se ne menja, pa novi rekordi i dalje dobijaju logNum veci od svih prethodnih.
+func (wal *WAL) DeleteSegments(index uint64) error {
+	wal.lock.Lock()
+	defer wal.lock.Unlock()
+	if wal.blockManager.GetBufferPool() != nil {
+		wal.blockManager.EmptyBufferPool()
+	}
+	kept := make([]string, 0, len(wal.segmentFilePaths))
+	for _, segment := range wal.segmentFilePaths {
+		number, err := segmentNumber(segment)
+		if err != nil {
+			return err
+		}
+		// aktivan segment se ne brise, u njega se i dalje pise
+		if number < index && segment != wal.activeSegmentPath {
+			if err := os.Remove(segment); err == nil || os.IsNotExist(err) {
+				continue
+			}
+			fmt.Printf("DeleteSegments: failed to remove %s: %v\n", segment, err)
+		}
+		kept = append(kept, segment)
+	}
+	wal.segmentFilePaths = kept
+
+	// NextRecord nastavlja u istom segmentu; ako je on obrisan, od pocetka prvog preostalog
+	for i, segment := range kept {
+		if segment == wal.currentRecordFilePath {
+			wal.currentRecordFilePathIndex = uint64(i)
+			return nil
+		}
+	}
+	wal.currentRecordFilePathIndex = 0
+	wal.currentRecordFilePath = ""
+	wal.currentRecordBlockNum = 1
+	wal.currentRecordIndex = 0
+	if len(kept) > 0 {
+		wal.currentRecordFilePath = kept[0]
+	}
+	return nil
+}
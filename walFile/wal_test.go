@@ -0,0 +1,214 @@
+package wal
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"project/blockmanager"
+	"testing"
+)
+
+const testBlockSize = 150
+
+// openTestWAL otvara WAL u dir sa malim blokovima i dva bloka po segmentu, da bi rekordi presli granice
+func openTestWAL(t *testing.T, dir string) *WAL {
+	t.Helper()
+	blockManager := blockmanager.NewBlockManager(blockmanager.NewBufferPool(), testBlockSize, testBlockSize*5)
+	return NewWal(2, dir, blockManager)
+}
+
+// testRecords pravi rekorde razlicitih velicina; veci od bloka se dele na delove koji prelaze u sledeci blok i segment
+func testRecords() []*blockmanager.Record {
+	records := make([]*blockmanager.Record, 0)
+	for i := 0; i < 12; i++ {
+		key := fmt.Sprintf("key-%02d", i)
+		spaceLeft := testBlockSize - blockmanager.RECORD_BASE_SIZE - len(key)
+		sizes := []int{5, 3 * spaceLeft, 2*spaceLeft + 7, 5, 20}
+		size := sizes[i%len(sizes)]
+		value := bytes.Repeat([]byte{byte('a' + i)}, size)
+		records = append(records, blockmanager.SetRec(0, uint64(i), 0, uint64(len(key)), uint64(size), key, value))
+	}
+	return records
+}
+
+func writeTestRecords(t *testing.T, wal *WAL, records []*blockmanager.Record) {
+	t.Helper()
+	for _, record := range records {
+		if err := wal.WriteRecord(record, wal.GetBlockManager()); err != nil {
+			t.Fatalf("WriteRecord %s: %v", record.GetKey(), err)
+		}
+	}
+}
+
+// replay cita sve rekorde od pocetka WAL-a, kao ucitavanje pri pokretanju
+func replay(wal *WAL) []*blockmanager.Record {
+	wal.ResetCounter()
+	records := make([]*blockmanager.Record, 0)
+	for {
+		record, hasNext := wal.NextRecord(wal.GetBlockManager())
+		if record == nil {
+			break
+		}
+		records = append(records, record)
+		if !hasNext {
+			break
+		}
+	}
+	return records
+}
+
+func checkRecords(t *testing.T, got, want []*blockmanager.Record) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("replayed %d records, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].GetKey() != want[i].GetKey() || !bytes.Equal(got[i].GetValue(), want[i].GetValue()) {
+			t.Fatalf("record %d is %s with %d bytes, want %s with %d bytes",
+				i, got[i].GetKey(), len(got[i].GetValue()), want[i].GetKey(), len(want[i].GetValue()))
+		}
+	}
+}
+
+func TestSplitRecordsAcrossSegments(t *testing.T) {
+	dir := t.TempDir()
+	records := testRecords()
+	wal := openTestWAL(t, dir)
+	writeTestRecords(t, wal, records)
+	if err := wal.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(wal.GetSegmentFilePaths()) < 3 {
+		t.Fatalf("records were written into %d segments, want them to span several", len(wal.GetSegmentFilePaths()))
+	}
+
+	wal = openTestWAL(t, dir)
+	defer wal.Close()
+	checkRecords(t, replay(wal), records)
+	if corruptions := wal.GetCorruptions(); len(corruptions) != 0 {
+		t.Fatalf("replay found corruptions: %v", corruptions)
+	}
+}
+
+func TestLoadSegmentsIgnoresOtherFiles(t *testing.T) {
+	dir := t.TempDir()
+	records := testRecords()
+	wal := openTestWAL(t, dir)
+	writeTestRecords(t, wal, records)
+	if err := wal.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"notes.txt", "wal_002.log.bak", "wal_x.log"} {
+		if err := os.WriteFile(filepath.Join(dir, "WAL", name), []byte("not a segment"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	wal = openTestWAL(t, dir)
+	defer wal.Close()
+	for _, path := range wal.GetSegmentFilePaths() {
+		if _, err := segmentNumber(path); err != nil {
+			t.Fatalf("loaded %s as a segment", path)
+		}
+	}
+	checkRecords(t, replay(wal), records)
+
+	if err := wal.DeleteSegments(2); err != nil {
+		t.Fatalf("DeleteSegments: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "WAL", "wal_001.log")); !os.IsNotExist(err) {
+		t.Fatalf("wal_001.log was not deleted: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "WAL", "notes.txt")); err != nil {
+		t.Fatalf("DeleteSegments removed a file that is not a segment: %v", err)
+	}
+}
+
+// corruptSecondBlock upisuje osam malih rekorda (po dva u bloku, cetiri bloka u dva segmenta)
+// i kvari vrednost prvog rekorda u drugom bloku prvog segmenta
+func corruptSecondBlock(t *testing.T, dir string) []*blockmanager.Record {
+	t.Helper()
+	records := make([]*blockmanager.Record, 0)
+	for i := 0; i < 8; i++ {
+		key := fmt.Sprintf("key-%02d", i)
+		records = append(records, blockmanager.SetRec(0, uint64(i), 0, uint64(len(key)), 5, key, []byte("value")))
+	}
+	wal := openTestWAL(t, dir)
+	writeTestRecords(t, wal, records)
+	if err := wal.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(wal.GetSegmentFilePaths()) != 2 {
+		t.Fatalf("records were written into %d segments, want 2", len(wal.GetSegmentFilePaths()))
+	}
+
+	path := filepath.Join(dir, "WAL", "wal_001.log")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[blockmanager.HEADER_SIZE+testBlockSize+blockmanager.RECORD_BASE_SIZE+len("key-02")] ^= 0xff
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return records
+}
+
+func TestRecoverySkipContinuesAfterCorruptBlock(t *testing.T) {
+	dir := t.TempDir()
+	records := corruptSecondBlock(t, dir)
+
+	wal := openTestWAL(t, dir)
+	defer wal.Close()
+	if err := wal.SetRecoveryMode(RECOVERY_SKIP); err != nil {
+		t.Fatal(err)
+	}
+	// ostatak ostecenog bloka (key-02 i key-03) se preskace, citanje se nastavlja od sledeceg bloka
+	want := append(append([]*blockmanager.Record(nil), records[:2]...), records[4:]...)
+	checkRecords(t, replay(wal), want)
+	corruptions := wal.GetCorruptions()
+	if len(corruptions) != 1 {
+		t.Fatalf("replay found %d corruptions, want 1", len(corruptions))
+	}
+	if c := corruptions[0]; c.Position.Block != 2 || c.Position.Index != 0 || c.Discarded == 0 {
+		t.Fatalf("corruption at block %d, index %d with %d bytes discarded, want block 2, index 0 and discarded bytes",
+			c.Position.Block, c.Position.Index, c.Discarded)
+	}
+}
+
+func TestRecoveryTruncateCutsWALAtCorruption(t *testing.T) {
+	dir := t.TempDir()
+	records := corruptSecondBlock(t, dir)
+
+	wal := openTestWAL(t, dir)
+	if err := wal.SetRecoveryMode(RECOVERY_TRUNCATE); err != nil {
+		t.Fatal(err)
+	}
+	checkRecords(t, replay(wal), records[:2])
+	corruptions := wal.GetCorruptions()
+	if len(corruptions) != 1 {
+		t.Fatalf("replay found %d corruptions, want 1", len(corruptions))
+	}
+	discarded, removed, err := wal.Truncate(corruptions[0])
+	if err != nil {
+		t.Fatalf("Truncate: %v", err)
+	}
+	if discarded == 0 || len(removed) != 1 || filepath.Base(removed[0]) != "wal_002.log" {
+		t.Fatalf("Truncate discarded %d bytes and removed %v, want wal_002.log removed", discarded, removed)
+	}
+
+	// novi rekordi se pisu odmah iza poslednjeg ispravnog
+	extra := blockmanager.SetRec(0, 8, 0, 6, 5, "key-08", []byte("value"))
+	writeTestRecords(t, wal, []*blockmanager.Record{extra})
+	if err := wal.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	wal = openTestWAL(t, dir)
+	defer wal.Close()
+	checkRecords(t, replay(wal), append(append([]*blockmanager.Record(nil), records[:2]...), extra))
+	if corruptions := wal.GetCorruptions(); len(corruptions) != 0 {
+		t.Fatalf("replay after truncate found corruptions: %v", corruptions)
+	}
+}